@@ -0,0 +1,105 @@
+package bverfg
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gocolly/colly"
+)
+
+const defaultBaseURL = "https://www.bundesverfassungsgericht.de"
+
+// Client scrapes the site of the court.
+type Client struct {
+	// HTTPClient defaults to http.DefaultClient. Its transport, timeout
+	// and cookie jar are used for scraping.
+	HTTPClient *http.Client
+	// BaseURL defaults to the court's site.
+	BaseURL string
+}
+
+// DefaultClient is used by the package level scrape functions.
+var DefaultClient = &Client{}
+
+func (c *Client) baseURL() string {
+	if c.BaseURL == "" {
+		return defaultBaseURL
+	}
+	return strings.TrimSuffix(c.BaseURL, "/")
+}
+
+// newCollector returns a collector making its requests with ctx.
+func (c *Client) newCollector(ctx context.Context) (*colly.Collector, error) {
+	base, err := url.Parse(c.baseURL())
+	if err != nil {
+		return nil, fmt.Errorf("parsing base url: %w", err)
+	}
+
+	collector := colly.NewCollector(colly.AllowedDomains(bverfgDomain, fmt.Sprintf("www.%s", bverfgDomain), base.Host))
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	collector.WithTransport(contextTransport{ctx: ctx, next: transport})
+	if httpClient.Timeout > 0 {
+		collector.SetRequestTimeout(httpClient.Timeout)
+	}
+	if httpClient.Jar == nil {
+		collector.DisableCookies()
+	}
+
+	collector.OnRequest(func(r *colly.Request) {
+		log.Println("scraping", r.URL.String())
+	})
+
+	return collector, nil
+}
+
+// contextTransport makes requests with a context, as colly doesn't support
+// them itself.
+type contextTransport struct {
+	ctx  context.Context
+	next http.RoundTripper
+}
+
+func (t contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.next.RoundTrip(req.WithContext(t.ctx))
+}
+
+// RowError is a table row of a scraped page that could not be parsed.
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// ScrapeError lists the rows of a page that could not be parsed. The rows
+// that could be parsed are returned along with it.
+type ScrapeError struct {
+	URL  string
+	Rows []RowError
+}
+
+func (e *ScrapeError) Error() string {
+	rows := make([]string, len(e.Rows))
+	for i, row := range e.Rows {
+		rows[i] = row.Error()
+	}
+	return fmt.Sprintf("scraping %v: %s", e.URL, strings.Join(rows, "; "))
+}
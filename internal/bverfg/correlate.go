@@ -0,0 +1,202 @@
+package bverfg
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	// correlationRetention is how long announced items are remembered to
+	// link them with later publications of the same case.
+	correlationRetention = 14 * 24 * time.Hour
+)
+
+// Correlated is a decision and/or press release to announce. If both are
+// set, they are merged into a single announcement.
+type Correlated struct {
+	Decision     *Decision
+	PressRelease *gofeed.Item
+
+	// RelatedDecision and RelatedPressRelease are the already announced
+	// counterparts of a single press release or decision, meant to be
+	// referenced only.
+	RelatedDecision     *Decision
+	RelatedPressRelease *gofeed.Item
+}
+
+// Merged reports whether a decision and its press release are announced
+// together.
+func (c Correlated) Merged() bool {
+	return c.Decision != nil && c.PressRelease != nil
+}
+
+// correlationEntry holds either a decision or a press release.
+type correlationEntry struct {
+	decision     *Decision
+	pressRelease *gofeed.Item
+	refs         []CaseReference
+	at           time.Time
+}
+
+// holdBack reports whether the entry is worth waiting for its counterpart.
+// Chamber decisions hardly ever come with a press release.
+func (e correlationEntry) holdBack() bool {
+	return len(e.refs) > 0 && (e.decision == nil || e.decision.Body != Kammer)
+}
+
+func (e correlationEntry) matches(other correlationEntry) bool {
+	if (e.decision == nil) == (other.decision == nil) {
+		return false
+	}
+	for _, ref := range e.refs {
+		for _, otherRef := range other.refs {
+			if ref == otherRef {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Correlator relates decisions and press releases concerning the same case
+// by their case references. Publications are held back for a window to
+// merge them with their counterpart if it arrives in time.
+//
+// The correlator is driven by the caller's clock, Expired has to be called
+// regularly to release the publications whose window elapsed.
+type Correlator struct {
+	mu      sync.Mutex
+	window  time.Duration
+	pending []correlationEntry // held back, waiting for their counterpart
+	history []correlationEntry // already announced
+}
+
+// NewCorrelator returns a correlator holding publications back for window.
+// Chamber decisions and publications without case references aren't held
+// back. With a zero window publications are released immediately, only
+// referencing their already announced counterparts.
+func NewCorrelator(window time.Duration) *Correlator {
+	return &Correlator{window: window}
+}
+
+// AddDecision adds a decision and returns what is ready to be announced.
+func (c *Correlator) AddDecision(d Decision, now time.Time) []Correlated {
+	return c.add(correlationEntry{decision: &d, refs: d.Refs, at: now})
+}
+
+// AddPressRelease adds a press release feed item and returns what is ready
+// to be announced.
+func (c *Correlator) AddPressRelease(item *gofeed.Item, now time.Time) []Correlated {
+	return c.add(correlationEntry{
+		pressRelease: item,
+		refs:         FindCaseRefs(item.Title + "\n" + item.Description),
+		at:           now,
+	})
+}
+
+func (c *Correlator) add(entry correlationEntry) []Correlated {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry.decision != nil && c.knows(entry.decision.ID()) {
+		return nil
+	}
+
+	// Counterpart still waiting, announce both at once
+	for i, pending := range c.pending {
+		if pending.matches(entry) {
+			c.pending = append(c.pending[:i], c.pending[i+1:]...)
+			c.remember(pending, entry)
+			return []Correlated{correlated(pending, entry)}
+		}
+	}
+
+	if c.window > 0 && entry.holdBack() {
+		c.pending = append(c.pending, entry)
+		return nil
+	}
+
+	return []Correlated{c.release(entry)}
+}
+
+// Expired returns the held back publications whose window elapsed.
+func (c *Correlator) Expired(now time.Time) []Correlated {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expired []Correlated
+	pending := c.pending[:0]
+	for _, entry := range c.pending {
+		if now.Sub(entry.at) < c.window {
+			pending = append(pending, entry)
+			continue
+		}
+		expired = append(expired, c.release(entry))
+	}
+	c.pending = pending
+
+	// Forget announcements too old to be referenced
+	history := c.history[:0]
+	for _, entry := range c.history {
+		if now.Sub(entry.at) < correlationRetention {
+			history = append(history, entry)
+		}
+	}
+	c.history = history
+
+	return expired
+}
+
+// knows reports whether a decision is pending or was announced already.
+func (c *Correlator) knows(decisionID string) bool {
+	for _, entries := range [][]correlationEntry{c.pending, c.history} {
+		for _, entry := range entries {
+			if entry.decision != nil && entry.decision.ID() == decisionID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// release announces a single entry, referencing its latest announced
+// counterpart if there is one.
+func (c *Correlator) release(entry correlationEntry) Correlated {
+	var counterpart *correlationEntry
+	for i := len(c.history) - 1; i >= 0; i-- {
+		if c.history[i].matches(entry) {
+			counterpart = &c.history[i]
+			break
+		}
+	}
+	c.remember(entry)
+
+	result := correlated(entry)
+	if counterpart != nil {
+		result.RelatedDecision = counterpart.decision
+		result.RelatedPressRelease = counterpart.pressRelease
+	}
+	return result
+}
+
+func (c *Correlator) remember(entries ...correlationEntry) {
+	for _, entry := range entries {
+		if len(entry.refs) > 0 {
+			c.history = append(c.history, entry)
+		}
+	}
+}
+
+func correlated(entries ...correlationEntry) Correlated {
+	var result Correlated
+	for _, entry := range entries {
+		if entry.decision != nil {
+			result.Decision = entry.decision
+		} else {
+			result.PressRelease = entry.pressRelease
+		}
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package bverfg_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jgraeger/bverfgbot/internal/bverfg"
+	"github.com/mmcdole/gofeed"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrelatorMergesWithinWindow(t *testing.T) {
+	c := bverfg.NewCorrelator(time.Hour)
+	now := time.Now()
+
+	decision := bverfg.DecisionFromItem(&gofeed.Item{Title: "Beschluss vom 1. März 2023 - 1 BvR 1/23"})
+	press := &gofeed.Item{Title: "Erfolglose Verfassungsbeschwerde", Description: "Beschluss vom 1. März 2023 - 1 BvR 1/23"}
+
+	assert.Empty(t, c.AddDecision(decision, now))
+
+	released := c.AddPressRelease(press, now.Add(10*time.Minute))
+	assert.Equal(t, []bverfg.Correlated{{Decision: &decision, PressRelease: press}}, released)
+	assert.True(t, released[0].Merged())
+
+	assert.Empty(t, c.Expired(now.Add(2*time.Hour)))
+}
+
+func TestCorrelatorReleasesExpired(t *testing.T) {
+	c := bverfg.NewCorrelator(time.Hour)
+	now := time.Now()
+
+	decision := bverfg.DecisionFromItem(&gofeed.Item{Title: "Urteil vom 1. März 2023 - 2 BvE 2/23"})
+	press := &gofeed.Item{Title: "Organstreit", Description: "2 BvE 2/23"}
+
+	assert.Empty(t, c.AddDecision(decision, now))
+	assert.Empty(t, c.Expired(now.Add(30*time.Minute)))
+	assert.Equal(t, []bverfg.Correlated{{Decision: &decision}}, c.Expired(now.Add(time.Hour)))
+
+	// Late press release references the already announced decision
+	assert.Empty(t, c.AddPressRelease(press, now.Add(2*time.Hour)))
+	assert.Equal(t,
+		[]bverfg.Correlated{{PressRelease: press, RelatedDecision: &decision}},
+		c.Expired(now.Add(3*time.Hour)),
+	)
+}
+
+func TestCorrelatorReleasesChamberDecisions(t *testing.T) {
+	c := bverfg.NewCorrelator(time.Hour)
+	now := time.Now()
+
+	decision := bverfg.DecisionFromItem(&gofeed.Item{
+		Title: "Beschluss - 1 BvR 2213/22",
+		Link:  "https://www.bundesverfassungsgericht.de/SharedDocs/Entscheidungen/DE/2023/01/rk20230112_1bvr221322.html",
+	})
+	assert.Equal(t, bverfg.Kammer, decision.Body)
+
+	assert.Equal(t, []bverfg.Correlated{{Decision: &decision}}, c.AddDecision(decision, now))
+}
+
+func TestCorrelatorWithoutWindow(t *testing.T) {
+	c := bverfg.NewCorrelator(0)
+	now := time.Now()
+
+	decision := bverfg.DecisionFromItem(&gofeed.Item{Title: "Beschluss - 1 BvL 3/22"})
+	press := &gofeed.Item{Title: "Normenkontrolle - 1 BvL 3/22"}
+	unrelated := &gofeed.Item{Title: "Jahresbericht"}
+
+	assert.Equal(t, []bverfg.Correlated{{Decision: &decision}}, c.AddDecision(decision, now))
+	assert.Equal(t,
+		[]bverfg.Correlated{{PressRelease: press, RelatedDecision: &decision}},
+		c.AddPressRelease(press, now),
+	)
+	assert.Equal(t, []bverfg.Correlated{{PressRelease: unrelated}}, c.AddPressRelease(unrelated, now))
+}
+
+func TestCorrelatorDeduplicatesDecisions(t *testing.T) {
+	c := bverfg.NewCorrelator(0)
+	now := time.Now()
+
+	decision := bverfg.DecisionFromItem(&gofeed.Item{
+		Title: "Beschluss - 1 BvR 1234/20",
+		Link:  "https://www.bundesverfassungsgericht.de/SharedDocs/Entscheidungen/DE/2023/01/rs20230131_1bvr123420.html",
+	})
+	republished := bverfg.DecisionFromItem(&gofeed.Item{
+		Title:       "Beschluss - 1 BvR 1234/20",
+		Description: "ECLI:DE:BVerfG:2023:rs20230131.1bvr123420",
+		Link:        "https://www.bundesverfassungsgericht.de/SharedDocs/Entscheidungen/DE/2023/01/rs20230131_1bvr123420.html?nn=1",
+	})
+
+	assert.Len(t, c.AddDecision(decision, now), 1)
+	assert.Empty(t, c.AddDecision(republished, now))
+}
@@ -0,0 +1,215 @@
+package bverfg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/goodsign/monday"
+	"github.com/mmcdole/gofeed"
+)
+
+// DecisionType is the form of a decision, e.g. a judgment after an oral
+// hearing or an order.
+type DecisionType string
+
+const (
+	Urteil     = DecisionType("Urteil")
+	Beschluss  = DecisionType("Beschluss")
+	Verfuegung = DecisionType("Verfügung")
+)
+
+// DecisionBody is the panel of the court that handed down a decision.
+type DecisionBody string
+
+const (
+	Senat  = DecisionBody("Senat")
+	Kammer = DecisionBody("Kammer")
+	Plenum = DecisionBody("Plenum")
+)
+
+// Decision is a published decision of the court.
+type Decision struct {
+	Refs   []CaseReference
+	Type   DecisionType
+	Body   DecisionBody
+	Senate uint8
+	// Date is the day the decision was handed down, not published. It is
+	// zero if neither the title nor the ECLI tell.
+	Date time.Time
+	// ECLI is zero if unknown.
+	ECLI ECLI
+	URL  string
+
+	Title       string
+	Description string
+
+	// Details is nil unless the decision page has been scraped.
+	Details *DecisionDetails
+}
+
+var (
+	decisionDateRegex = regexp.MustCompile(`vom (\d{1,2}\. [[:alpha:]äÄ]+ \d{4})`)
+	// Decision pages are named after the ECLI, e.g. rk20230112_1bvr221322.html
+	decisionPageRegex = regexp.MustCompile(`/([a-z]{2})((\d{4})\d{4})_(\d?[a-z]+\d+)\.html`)
+)
+
+// DecisionFromItem converts a decision feed item into a Decision. Fields
+// that cannot be derived from the item are left empty.
+func DecisionFromItem(item *gofeed.Item) Decision {
+	d := Decision{
+		Refs:        FindCaseRefs(item.Title + "\n" + item.Description),
+		URL:         item.Link,
+		Title:       item.Title,
+		Description: item.Description,
+	}
+
+	switch {
+	case strings.Contains(item.Title, string(Urteil)):
+		d.Type = Urteil
+	case strings.Contains(item.Title, string(Beschluss)):
+		d.Type = Beschluss
+	case strings.Contains(item.Title, string(Verfuegung)):
+		d.Type = Verfuegung
+	}
+
+	switch {
+	case strings.Contains(item.Title, "Kammer"):
+		d.Body = Kammer
+	case strings.Contains(item.Title, "Plenums"):
+		d.Body = Plenum
+	default:
+		d.Body = Senat
+	}
+
+	switch {
+	case strings.Contains(item.Title, "Ersten Senats"):
+		d.Senate = 1
+	case strings.Contains(item.Title, "Zweiten Senats"):
+		d.Senate = 2
+	case len(d.Refs) > 0:
+		d.Senate = d.Refs[0].Senate
+	}
+
+	d.Date = parseDecisionDate(item)
+
+	// The ECLI is authoritative, the title is not always complete
+	if ecli, ok := findECLI(item); ok {
+		d.ECLI = ecli
+		d.Type = ecli.Type
+		d.Body = ecli.Body
+		d.Date = ecli.Date
+		if d.Senate == 0 {
+			d.Senate = ecli.Ref.Senate
+		}
+		if !containsCaseRef(d.Refs, ecli.Ref) {
+			d.Refs = append([]CaseReference{ecli.Ref}, d.Refs...)
+		}
+		if d.URL == "" {
+			d.URL = ecli.URL()
+		}
+	}
+
+	return d
+}
+
+// LoadDetails scrapes the page of the decision for its details.
+func (d *Decision) LoadDetails(ctx context.Context) error {
+	if d.URL == "" {
+		return fmt.Errorf("no page known for decision %v", d.RefString())
+	}
+
+	details, err := GetDecisionDetails(ctx, d.URL)
+	if err != nil {
+		return err
+	}
+	d.Details = &details
+
+	return nil
+}
+
+// Headnotes returns the Leitsätze of the decision, if its details are loaded.
+func (d Decision) Headnotes() []string {
+	if d.Details == nil {
+		return nil
+	}
+	return d.Details.Headnotes
+}
+
+// HasDissent reports whether the decision is known to come with a
+// dissenting opinion.
+func (d Decision) HasDissent() bool {
+	return d.Details != nil && len(d.Details.Dissents) > 0
+}
+
+// Dissenters returns the judges of the dissenting opinions, if the details
+// of the decision are loaded.
+func (d Decision) Dissenters() []string {
+	if d.Details == nil {
+		return nil
+	}
+	return d.Details.Dissenters()
+}
+
+// ID identifies the decision, preferably by its ECLI as the same decision
+// may be published under different links.
+func (d Decision) ID() string {
+	if !d.ECLI.IsZero() {
+		return d.ECLI.String()
+	}
+	return d.URL
+}
+
+func containsCaseRef(refs []CaseReference, ref CaseReference) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// CourtLocation returns the time zone of the court.
+func CourtLocation() *time.Location {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// parseDecisionDate takes the date from the title, e.g. "Beschluss vom
+// 12. Januar 2023".
+func parseDecisionDate(item *gofeed.Item) time.Time {
+	if match := decisionDateRegex.FindStringSubmatch(item.Title); match != nil {
+		date, err := monday.ParseInLocation(monday.DefaultFormatDeDELong, match[1], CourtLocation(), monday.LocaleDeDE)
+		if err == nil {
+			return date
+		}
+	}
+	return time.Time{}
+}
+
+// findECLI takes the ECLI mentioned in the item, falling back to deriving
+// it from the name of the decision page.
+func findECLI(item *gofeed.Item) (ECLI, bool) {
+	if match := ecliRegex.FindString(item.Title + "\n" + item.Description); match != "" {
+		if ecli, err := ParseECLI(match); err == nil {
+			return ecli, true
+		}
+	}
+
+	match := decisionPageRegex.FindStringSubmatch(item.Link)
+	if match == nil {
+		return ECLI{}, false
+	}
+	ecli, err := ParseECLI(fmt.Sprintf("%s%s:%s%s.%s", ecliPrefix, match[3], match[1], match[2], match[4]))
+	return ecli, err == nil
+}
+
+// RefString returns the case references of the decision separated by comma.
+func (d Decision) RefString() string {
+	return JoinCaseRefs(d.Refs)
+}
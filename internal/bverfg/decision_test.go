@@ -0,0 +1,96 @@
+package bverfg_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jgraeger/bverfgbot/internal/bverfg"
+	"github.com/mmcdole/gofeed"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecisionFromItem(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	published := time.Date(2023, time.February, 1, 9, 0, 0, 0, loc)
+
+	testCases := []struct {
+		name     string
+		item     *gofeed.Item
+		expected bverfg.Decision
+	}{
+		{
+			name: "Chamber decision with ECLI from link",
+			item: &gofeed.Item{
+				Title: "Beschluss der 3. Kammer des Ersten Senats vom 12. Januar 2023 - 1 BvR 2213/22",
+				Link:  "https://www.bundesverfassungsgericht.de/SharedDocs/Entscheidungen/DE/2023/01/rk20230112_1bvr221322.html",
+			},
+			expected: bverfg.Decision{
+				Refs: []bverfg.CaseReference{
+					{Senate: 1, Type: bverfg.Verfassungsbeschwerde, RunningNumber: 2213, Year: 2022},
+				},
+				Type:   bverfg.Beschluss,
+				Body:   bverfg.Kammer,
+				Senate: 1,
+				Date:   time.Date(2023, time.January, 12, 0, 0, 0, 0, loc),
+				ECLI: bverfg.NewECLI(
+					bverfg.CaseReference{Senate: 1, Type: bverfg.Verfassungsbeschwerde, RunningNumber: 2213, Year: 2022},
+					bverfg.Beschluss, bverfg.Kammer, time.Date(2023, time.January, 12, 0, 0, 0, 0, loc),
+				),
+				URL:   "https://www.bundesverfassungsgericht.de/SharedDocs/Entscheidungen/DE/2023/01/rk20230112_1bvr221322.html",
+				Title: "Beschluss der 3. Kammer des Ersten Senats vom 12. Januar 2023 - 1 BvR 2213/22",
+			},
+		},
+		{
+			name: "Senate judgment with ECLI in description",
+			item: &gofeed.Item{
+				Title:       "Urteil des Zweiten Senats vom 15. November 2023 - 2 BvF 1/22",
+				Description: "ECLI:DE:BVerfG:2023:us20231115.2bvf000122",
+			},
+			expected: bverfg.Decision{
+				Refs: []bverfg.CaseReference{
+					{Senate: 2, Type: bverfg.AbstrakteNormenkontrolle, RunningNumber: 1, Year: 2022},
+				},
+				Type:   bverfg.Urteil,
+				Body:   bverfg.Senat,
+				Senate: 2,
+				Date:   time.Date(2023, time.November, 15, 0, 0, 0, 0, loc),
+				ECLI: bverfg.NewECLI(
+					bverfg.CaseReference{Senate: 2, Type: bverfg.AbstrakteNormenkontrolle, RunningNumber: 1, Year: 2022},
+					bverfg.Urteil, bverfg.Senat, time.Date(2023, time.November, 15, 0, 0, 0, 0, loc),
+				),
+				URL:         "https://www.bundesverfassungsgericht.de/SharedDocs/Entscheidungen/DE/2023/11/us20231115_2bvf000122.html",
+				Title:       "Urteil des Zweiten Senats vom 15. November 2023 - 2 BvF 1/22",
+				Description: "ECLI:DE:BVerfG:2023:us20231115.2bvf000122",
+			},
+		},
+		{
+			name: "Decision without date isn't dated by publication",
+			item: &gofeed.Item{
+				Title:           "Beschluss - 1 BvR 2213/22",
+				PublishedParsed: &published,
+			},
+			expected: bverfg.Decision{
+				Refs: []bverfg.CaseReference{
+					{Senate: 1, Type: bverfg.Verfassungsbeschwerde, RunningNumber: 2213, Year: 2022},
+				},
+				Type:   bverfg.Beschluss,
+				Body:   bverfg.Senat,
+				Senate: 1,
+				Title:  "Beschluss - 1 BvR 2213/22",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			d := bverfg.DecisionFromItem(tc.item)
+			assert.True(t, tc.expected.Date.Equal(d.Date), "date %v", d.Date)
+			d.Date = tc.expected.Date
+			d.ECLI.Date = tc.expected.ECLI.Date
+			assert.Equal(t, tc.expected, d)
+		})
+	}
+}
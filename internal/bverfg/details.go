@@ -0,0 +1,214 @@
+package bverfg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly"
+	"github.com/goodsign/monday"
+)
+
+const (
+	headnotesHeading = "Leitsätze"
+	pdfLinkSelector  = `a[href$=".pdf"], a[href*="blob=publicationFile"]`
+	// judgesIntro starts the paragraph naming the panel of the decision.
+	judgesIntro = "unter Mitwirkung der Richterinnen und Richter"
+)
+
+var (
+	// dissentRegex matches the heading of a dissenting opinion, e.g.
+	// "Abweichende Meinung der Richterin Britz und des Richters Radtke zum
+	// Urteil des Zweiten Senats".
+	dissentRegex      = regexp.MustCompile(`^(?:Abweichende Meinung|Sondervotum)\s+(?:der|des)\s+(.+?)(?:\s+zu[mr]?\s.*)?$`)
+	dissentTitleRegex = regexp.MustCompile(`\b(?:der|des|Richterin(?:nen)?|Richters?|Vizepräsident(?:in|en)?|Präsident(?:in|en)?)\s+`)
+	// tenorIntroRegex matches the end of the rubrum, right before the tenor.
+	tenorIntroRegex = regexp.MustCompile(`(?:beschlossen|für Recht erkannt|verfügt)\s*:\s*$`)
+	// reasonsRegex matches the heading of the reasons, which may be spaced
+	// out like "G r ü n d e :".
+	reasonsRegex    = regexp.MustCompile(`^(?:G\s?r\s?ü\s?n\s?d\s?e|Gründe)\s*:?$`)
+	judgeTitleRegex = regexp.MustCompile(`^(?:Vize)?[Pp]räsident(?:in)?\s+`)
+	judgeSplitRegex = regexp.MustCompile(`\s*(?:,|\bund\b)\s*`)
+	whitespaceRegex = regexp.MustCompile(`\s+`)
+)
+
+// DecisionDetails holds what is published on the page of a decision
+// besides its feed item.
+type DecisionDetails struct {
+	// ECLI is zero if the page doesn't mention it.
+	ECLI ECLI
+	Date time.Time
+	// Headnotes are the Leitsätze, only Senate decisions have them.
+	Headnotes []string
+	Tenor     []string
+	Judges    []string
+	// Dissents are the dissenting opinions (Sondervoten).
+	Dissents []Dissent
+	// Paragraphs are the numbered paragraphs (Rn.) of the reasons.
+	Paragraphs []Paragraph
+	PDFURL     string
+}
+
+// Paragraph is a numbered paragraph of a decision.
+type Paragraph struct {
+	Number int
+	Text   string
+}
+
+// Dissent is a dissenting opinion (Sondervotum).
+type Dissent struct {
+	Judges []string
+}
+
+// Dissenters returns the judges of all dissenting opinions.
+func (d DecisionDetails) Dissenters() []string {
+	var judges []string
+	for _, dissent := range d.Dissents {
+		judges = append(judges, dissent.Judges...)
+	}
+	return judges
+}
+
+// decisionSection is the part of a decision page being scraped.
+type decisionSection int
+
+const (
+	rubrumSection decisionSection = iota
+	headnotesSection
+	tenorSection
+	reasonsSection
+)
+
+// GetDecisionDetails scrapes the page of a decision using the DefaultClient.
+func GetDecisionDetails(ctx context.Context, url string) (DecisionDetails, error) {
+	return DefaultClient.GetDecisionDetails(ctx, url)
+}
+
+// GetDecisionDetails scrapes the page of a decision.
+func (c *Client) GetDecisionDetails(ctx context.Context, url string) (DecisionDetails, error) {
+	var (
+		details DecisionDetails
+		found   bool
+	)
+
+	collector, err := c.newCollector(ctx)
+	if err != nil {
+		return DecisionDetails{}, err
+	}
+
+	collector.OnHTML(`body`, func(h *colly.HTMLElement) {
+		found = true
+
+		if match := ecliRegex.FindString(h.Text); match != "" {
+			if ecli, err := ParseECLI(match); err == nil {
+				details.ECLI = ecli
+				details.Date = ecli.Date
+			}
+		}
+		if details.Date.IsZero() {
+			if match := decisionDateRegex.FindStringSubmatch(h.Text); match != nil {
+				date, err := monday.ParseInLocation(monday.DefaultFormatDeDELong, match[1], CourtLocation(), monday.LocaleDeDE)
+				if err == nil {
+					details.Date = date
+				}
+			}
+		}
+
+		if href, ok := h.DOM.Find(pdfLinkSelector).First().Attr("href"); ok {
+			details.PDFURL = h.Request.AbsoluteURL(href)
+		}
+
+		section := rubrumSection
+		h.DOM.Find("h1, h2, h3, h4, p").Each(func(_ int, s *goquery.Selection) {
+			text := normalizeSpace(s.Text())
+			if text == "" {
+				return
+			}
+
+			switch {
+			case text == headnotesHeading:
+				section = headnotesSection
+				return
+			case reasonsRegex.MatchString(text):
+				section = reasonsSection
+				return
+			case section == reasonsSection && !s.Is("p") && dissentRegex.MatchString(text):
+				judges := dissentRegex.FindStringSubmatch(text)[1]
+				judges = dissentTitleRegex.ReplaceAllString(judges, "")
+				details.Dissents = append(details.Dissents, Dissent{Judges: parseJudges(judges)})
+				return
+			case strings.HasPrefix(text, judgesIntro):
+				details.Judges = parseJudges(strings.TrimPrefix(text, judgesIntro))
+				section = rubrumSection
+			case tenorIntroRegex.MatchString(text):
+				section = tenorSection
+				return
+			case section == headnotesSection && !s.Is("p"):
+				// Headnotes end with the heading of the decision
+				section = rubrumSection
+			}
+
+			switch section {
+			case headnotesSection:
+				if s.Is("p") {
+					details.Headnotes = append(details.Headnotes, text)
+				}
+			case tenorSection:
+				if s.Is("p") {
+					details.Tenor = append(details.Tenor, text)
+				}
+			case reasonsSection:
+				if p, ok := parseParagraph(s); ok {
+					details.Paragraphs = append(details.Paragraphs, p)
+				}
+			}
+		})
+	})
+
+	if err := collector.Visit(url); err != nil {
+		return DecisionDetails{}, fmt.Errorf("visiting decision page: %w", err)
+	}
+	if !found {
+		return DecisionDetails{}, fmt.Errorf("no decision found on %v", url)
+	}
+
+	return details, nil
+}
+
+// parseParagraph parses a paragraph with its number (Rn.) in a span.
+func parseParagraph(s *goquery.Selection) (Paragraph, bool) {
+	rn := s.Find(".rn").First()
+	if rn.Length() == 0 {
+		return Paragraph{}, false
+	}
+
+	number, err := strconv.Atoi(normalizeSpace(rn.Text()))
+	if err != nil {
+		return Paragraph{}, false
+	}
+
+	rnText := rn.Text()
+	text := strings.Replace(s.Text(), rnText, "", 1)
+	return Paragraph{Number: number, Text: normalizeSpace(text)}, true
+}
+
+// parseJudges parses the judges listed like "Präsident Harbarth, Baer und
+// Britz".
+func parseJudges(list string) []string {
+	var judges []string
+	for _, name := range judgeSplitRegex.Split(strings.TrimSuffix(strings.TrimSpace(list), "."), -1) {
+		name = judgeTitleRegex.ReplaceAllString(name, "")
+		if name != "" {
+			judges = append(judges, name)
+		}
+	}
+	return judges
+}
+
+func normalizeSpace(s string) string {
+	return strings.TrimSpace(whitespaceRegex.ReplaceAllString(s, " "))
+}
@@ -0,0 +1,53 @@
+package bverfg_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jgraeger/bverfgbot/internal/bverfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrapeDecisionDetails(t *testing.T) {
+	srv := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer srv.Close()
+
+	client := &bverfg.Client{BaseURL: srv.URL}
+	details, err := client.GetDecisionDetails(context.Background(), srv.URL+"/decision.html")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "ECLI:DE:BVerfG:2023:rs20230131.1bvr123420", details.ECLI.String())
+	assert.Equal(t, "2023-01-31", details.Date.Format("2006-01-02"))
+	assert.Equal(t, []string{
+		"1. Das Grundrecht auf informationelle Selbstbestimmung schützt auch vor Datenabgleichen.",
+		"2. Eingriffe bedürfen einer hinreichend bestimmten gesetzlichen Grundlage.",
+	}, details.Headnotes)
+	assert.Equal(t, []string{
+		"1. § 25a Absatz 1 HSOG ist mit Artikel 2 Absatz 1 des Grundgesetzes unvereinbar.",
+		"2. Das Land Hessen hat dem Beschwerdeführer seine notwendigen Auslagen zu erstatten.",
+	}, details.Tenor)
+	assert.Equal(t, []string{"Harbarth", "Baer", "Britz", "Ott", "Christ"}, details.Judges)
+	assert.Equal(t, []bverfg.Paragraph{
+		{Number: 1, Text: "Die Verfassungsbeschwerde betrifft die automatisierte Datenanalyse."},
+		{Number: 2, Text: "Der Beschwerdeführer ist Rechtsanwalt."},
+		{Number: 3, Text: "Wir tragen die Entscheidung nicht mit."},
+		{Number: 4, Text: "Ich stimme nicht zu."},
+	}, details.Paragraphs)
+	assert.Equal(t, []bverfg.Dissent{
+		{Judges: []string{"Britz", "Christ"}},
+		{Judges: []string{"Ott"}},
+	}, details.Dissents)
+	assert.Equal(t, []string{"Britz", "Christ", "Ott"}, details.Dissenters())
+	assert.Equal(t, srv.URL+"/SharedDocs/Downloads/DE/2023/01/rs20230131_1bvr123420.pdf?__blob=publicationFile&v=1", details.PDFURL)
+}
+
+func TestScrapeDecisionDetailsNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	client := &bverfg.Client{BaseURL: srv.URL}
+	_, err := client.GetDecisionDetails(context.Background(), srv.URL)
+	assert.Error(t, err)
+}
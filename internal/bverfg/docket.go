@@ -0,0 +1,101 @@
+package bverfg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gocolly/colly"
+)
+
+// docketPreviewPath is the page of the annual docket preview of a year.
+const docketPreviewPath = "/DE/Verfahren/Jahresvorausschau/vs_%[1]d/vorausschau_%[1]d_node.html"
+
+// DocketEntry is a proceeding the court intends to decide within a year,
+// as published in its annual docket preview (Jahresvorausschau).
+type DocketEntry struct {
+	Ref     CaseReference
+	Subject string
+	// Rapporteur is the judge in charge of the proceeding (Berichterstatter).
+	Rapporteur string
+}
+
+// DocketPreview is the annual docket preview of a year.
+type DocketPreview struct {
+	Year    int
+	Entries []DocketEntry
+}
+
+// Entry returns the entry of a case, if it is on the docket.
+func (p DocketPreview) Entry(ref CaseReference) (DocketEntry, bool) {
+	for _, entry := range p.Entries {
+		if entry.Ref == ref {
+			return entry, true
+		}
+	}
+	return DocketEntry{}, false
+}
+
+// GetDocketPreview scrapes the annual docket preview of a year using the
+// DefaultClient.
+func GetDocketPreview(ctx context.Context, year int) (DocketPreview, error) {
+	return DefaultClient.GetDocketPreview(ctx, year)
+}
+
+// GetDocketPreview scrapes the annual docket preview of a year. Joined
+// proceedings result in an entry per case reference. Rows that cannot be
+// parsed are skipped and reported by a *ScrapeError.
+func (c *Client) GetDocketPreview(ctx context.Context, year int) (DocketPreview, error) {
+	preview := DocketPreview{Year: year}
+
+	collector, err := c.newCollector(ctx)
+	if err != nil {
+		return DocketPreview{}, err
+	}
+
+	pageURL := c.baseURL() + fmt.Sprintf(docketPreviewPath, year)
+	scrapeErr := &ScrapeError{URL: pageURL}
+	seen := make(map[CaseReference]bool)
+	row := 0
+
+	collector.OnHTML(`table tr`, func(h *colly.HTMLElement) {
+		dataCells := h.DOM.Find("td")
+		if dataCells.Length() == 0 {
+			return
+		}
+		row++
+
+		caseRefStr := normalizeSpace(dataCells.Eq(0).Text())
+		subject := normalizeSpace(dataCells.Eq(1).Text())
+		rapporteur := normalizeSpace(dataCells.Eq(2).Text())
+
+		if caseRefStr == "" {
+			return
+		}
+
+		caseRefs, err := ParseCaseRefs(caseRefStr)
+		if err != nil {
+			scrapeErr.Rows = append(scrapeErr.Rows, RowError{Row: row, Err: err})
+		}
+
+		for _, ref := range caseRefs {
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+			preview.Entries = append(preview.Entries, DocketEntry{
+				Ref:        ref,
+				Subject:    subject,
+				Rapporteur: rapporteur,
+			})
+		}
+	})
+
+	if err := collector.Visit(pageURL); err != nil {
+		return DocketPreview{}, fmt.Errorf("visiting docket preview page: %w", err)
+	}
+
+	if len(scrapeErr.Rows) > 0 {
+		return preview, scrapeErr
+	}
+	return preview, nil
+}
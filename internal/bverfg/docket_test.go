@@ -0,0 +1,45 @@
+package bverfg_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jgraeger/bverfgbot/internal/bverfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDocketPreview(t *testing.T) {
+	var requested string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		http.ServeFile(w, r, "testdata/docket_preview.html")
+	}))
+	defer srv.Close()
+
+	client := &bverfg.Client{BaseURL: srv.URL}
+	preview, err := client.GetDocketPreview(context.Background(), 2023)
+	assert.Equal(t, "/DE/Verfahren/Jahresvorausschau/vs_2023/vorausschau_2023_node.html", requested)
+
+	var scrapeErr *bverfg.ScrapeError
+	if assert.ErrorAs(t, err, &scrapeErr) {
+		assert.Len(t, scrapeErr.Rows, 1)
+	}
+
+	assert.Equal(t, 2023, preview.Year)
+	assert.Len(t, preview.Entries, 4)
+
+	entry, ok := preview.Entry(bverfg.CaseReference{
+		Senate: 1, Type: bverfg.Verfassungsbeschwerde, RunningNumber: 2650, Year: 2021,
+	})
+	assert.True(t, ok)
+	assert.Equal(t, bverfg.DocketEntry{
+		Ref:        bverfg.CaseReference{Senate: 1, Type: bverfg.Verfassungsbeschwerde, RunningNumber: 2650, Year: 2021},
+		Subject:    "Einrichtungsbezogene Impfpflicht",
+		Rapporteur: "Richter Christ",
+	}, entry)
+
+	_, ok = preview.Entry(bverfg.CaseReference{Senate: 2, Type: bverfg.Organstreit, RunningNumber: 5, Year: 2020})
+	assert.False(t, ok)
+}
@@ -0,0 +1,133 @@
+package bverfg
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ecliPrefix = "ECLI:DE:BVerfG:"
+	ecliDate   = "20060102"
+
+	decisionBaseURL = "https://www.bundesverfassungsgericht.de/SharedDocs/Entscheidungen/DE"
+)
+
+var ecliRegex = regexp.MustCompile(`ECLI:DE:BVerfG:(\d{4}):([a-z])([a-z])(\d{8})\.([12]?)([a-z]+)(\d+)(\d{2})`)
+
+// ECLI is the European Case Law Identifier of a decision, e.g.
+// ECLI:DE:BVerfG:2023:rs20230131.1bvr123420 for a decision of the first
+// senate on 31 January 2023 in the case 1 BvR 1234/20.
+type ECLI struct {
+	Type DecisionType
+	Body DecisionBody
+	Date time.Time
+	Ref  CaseReference
+}
+
+// NewECLI returns the ECLI of a decision.
+func NewECLI(ref CaseReference, typ DecisionType, body DecisionBody, date time.Time) ECLI {
+	return ECLI{Type: typ, Body: body, Date: date, Ref: ref}
+}
+
+// ParseECLI parses an ECLI of the court.
+func ParseECLI(s string) (ECLI, error) {
+	match := ecliRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil || match[0] != strings.TrimSpace(s) {
+		return ECLI{}, fmt.Errorf("invalid ecli: %q", s)
+	}
+
+	var e ECLI
+	switch match[2] {
+	case "u":
+		e.Type = Urteil
+	case "r":
+		e.Type = Beschluss
+	default:
+		return ECLI{}, fmt.Errorf("invalid ecli decision type: %v", match[2])
+	}
+
+	switch match[3] {
+	case "s":
+		e.Body = Senat
+	case "k":
+		e.Body = Kammer
+	case "p":
+		e.Body = Plenum
+	default:
+		return ECLI{}, fmt.Errorf("invalid ecli decision body: %v", match[3])
+	}
+
+	date, err := time.ParseInLocation(ecliDate, match[4], CourtLocation())
+	if err != nil {
+		return ECLI{}, fmt.Errorf("parse ecli date: %w", err)
+	}
+	if strconv.Itoa(date.Year()) != match[1] {
+		return ECLI{}, fmt.Errorf("ecli year %v doesn't match decision date %v", match[1], match[4])
+	}
+	e.Date = date
+
+	ref, err := ParseCaseRefStrict(fmt.Sprintf("%s %s %s/%s", match[5], registerFromECLI(match[6]), match[7], match[8]))
+	if err != nil {
+		return ECLI{}, fmt.Errorf("parse ecli case ref: %w", err)
+	}
+	e.Ref = ref
+
+	return e, nil
+}
+
+// registerFromECLI restores the case of a lower cased register.
+func registerFromECLI(register string) string {
+	for _, p := range procedureTypes {
+		if strings.ToLower(p.RefSign()) == register {
+			return p.RefSign()
+		}
+	}
+	return register
+}
+
+// IsZero reports whether the ECLI is unknown.
+func (e ECLI) IsZero() bool {
+	return e.Date.IsZero()
+}
+
+func (e ECLI) String() string {
+	return fmt.Sprintf("%s%d:%s", ecliPrefix, e.Date.Year(), e.documentName("."))
+}
+
+// URL returns the canonical link to the decision on the court's site.
+func (e ECLI) URL() string {
+	return fmt.Sprintf("%s/%d/%02d/%s.html", decisionBaseURL, e.Date.Year(), e.Date.Month(), e.documentName("_"))
+}
+
+// documentName formats the decision specific part of the ECLI, e.g.
+// rs20230131.1bvr123420, joined by sep.
+func (e ECLI) documentName(sep string) string {
+	var typ, body string
+	switch e.Type {
+	case Urteil:
+		typ = "u"
+	default:
+		typ = "r"
+	}
+	switch e.Body {
+	case Kammer:
+		body = "k"
+	case Plenum:
+		body = "p"
+	default:
+		body = "s"
+	}
+
+	senate := ""
+	if e.Ref.Senate != 0 {
+		senate = strconv.Itoa(int(e.Ref.Senate))
+	}
+
+	return fmt.Sprintf("%s%s%s%s%s%s%04d%02d",
+		typ, body, e.Date.Format(ecliDate), sep,
+		senate, strings.ToLower(e.Ref.Type.RefSign()), e.Ref.RunningNumber, e.Ref.Year%100,
+	)
+}
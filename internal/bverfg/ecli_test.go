@@ -0,0 +1,101 @@
+package bverfg_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jgraeger/bverfgbot/internal/bverfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseECLI(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      string
+		shouldFail bool
+		expected   bverfg.ECLI
+	}{
+		{
+			name:  "Parse senate order",
+			input: "ECLI:DE:BVerfG:2023:rs20230131.1bvr123420",
+			expected: bverfg.ECLI{
+				Type: bverfg.Beschluss,
+				Body: bverfg.Senat,
+				Date: time.Date(2023, time.January, 31, 0, 0, 0, 0, time.UTC),
+				Ref:  bverfg.CaseReference{Senate: 1, Type: bverfg.Verfassungsbeschwerde, RunningNumber: 1234, Year: 2020},
+			},
+		},
+		{
+			name:  "Parse chamber order with padded running number",
+			input: "ECLI:DE:BVerfG:2022:rk20221005.2bve000122",
+			expected: bverfg.ECLI{
+				Type: bverfg.Beschluss,
+				Body: bverfg.Kammer,
+				Date: time.Date(2022, time.October, 5, 0, 0, 0, 0, time.UTC),
+				Ref:  bverfg.CaseReference{Senate: 2, Type: bverfg.Organstreit, RunningNumber: 1, Year: 2022},
+			},
+		},
+		{
+			name:  "Parse plenary judgment",
+			input: "ECLI:DE:BVerfG:2012:up20120103.pbvu000111",
+			expected: bverfg.ECLI{
+				Type: bverfg.Urteil,
+				Body: bverfg.Plenum,
+				Date: time.Date(2012, time.January, 3, 0, 0, 0, 0, time.UTC),
+				Ref:  bverfg.CaseReference{Type: bverfg.Plenarentscheidung, RunningNumber: 1, Year: 2011},
+			},
+		},
+		{
+			name:       "Error with other court",
+			input:      "ECLI:DE:BGH:2023:rs20230131.1bvr123420",
+			shouldFail: true,
+		},
+		{
+			name:       "Error with mismatching year",
+			input:      "ECLI:DE:BVerfG:2022:rs20230131.1bvr123420",
+			shouldFail: true,
+		},
+		{
+			name:       "Error with unknown register",
+			input:      "ECLI:DE:BVerfG:2023:rs20230131.1bvx123420",
+			shouldFail: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			parsed, err := bverfg.ParseECLI(tc.input)
+			if tc.shouldFail {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected.Date.Format("2006-01-02"), parsed.Date.Format("2006-01-02"))
+			parsed.Date = tc.expected.Date
+			assert.Equal(t, tc.expected, parsed)
+		})
+	}
+}
+
+func TestECLIRoundTrip(t *testing.T) {
+	for _, input := range []string{
+		"ECLI:DE:BVerfG:2023:rs20230131.1bvr123420",
+		"ECLI:DE:BVerfG:2023:us20231115.2bvf000122",
+		"ECLI:DE:BVerfG:2012:up20120103.pbvu000111",
+	} {
+		parsed, err := bverfg.ParseECLI(input)
+		assert.NoError(t, err)
+		assert.Equal(t, input, parsed.String())
+	}
+}
+
+func TestECLIURL(t *testing.T) {
+	ecli, err := bverfg.ParseECLI("ECLI:DE:BVerfG:2023:rk20230112.1bvr221322")
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"https://www.bundesverfassungsgericht.de/SharedDocs/Entscheidungen/DE/2023/01/rk20230112_1bvr221322.html",
+		ecli.URL(),
+	)
+}
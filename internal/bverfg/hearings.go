@@ -0,0 +1,127 @@
+package bverfg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly"
+	"github.com/goodsign/monday"
+)
+
+const hearingsPath = "/DE/Verfahren/Muendliche-Verhandlungen/muendliche-verhandlungen_node.html"
+
+// hearingDateRegex matches dates like "Dienstag, 14. März 2023, 10:00 Uhr",
+// the time being optional.
+var hearingDateRegex = regexp.MustCompile(`(\d{1,2}\. [[:alpha:]äÄ]+ \d{4})(?:,?\s*(\d{1,2})[.:](\d{2})\s*Uhr)?`)
+
+// AnnouncedHearing is a scheduled oral hearing (mündliche Verhandlung).
+type AnnouncedHearing struct {
+	Refs    []CaseReference
+	Subject string
+	// Date holds the start of the hearing, at midnight if the time of day
+	// isn't announced.
+	Date     time.Time
+	Location string
+}
+
+// ID identifies the hearing, a case may be heard on several days.
+func (h AnnouncedHearing) ID() string {
+	return fmt.Sprintf("%s@%s", h.RefString(), h.Date.Format(time.RFC3339))
+}
+
+// RefString returns the case references of the hearing separated by comma.
+func (h AnnouncedHearing) RefString() string {
+	return JoinCaseRefs(h.Refs)
+}
+
+// GetUpcomingHearings scrapes the scheduled oral hearings using the
+// DefaultClient.
+func GetUpcomingHearings(ctx context.Context) ([]AnnouncedHearing, error) {
+	return DefaultClient.GetUpcomingHearings(ctx)
+}
+
+// GetUpcomingHearings scrapes the scheduled oral hearings. Rows that cannot
+// be parsed are skipped and reported by a *ScrapeError.
+func (c *Client) GetUpcomingHearings(ctx context.Context) ([]AnnouncedHearing, error) {
+	var hearings []AnnouncedHearing
+
+	collector, err := c.newCollector(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pageURL := c.baseURL() + hearingsPath
+	scrapeErr := &ScrapeError{URL: pageURL}
+	row := 0
+
+	collector.OnHTML(`table tr`, func(h *colly.HTMLElement) {
+		dataCells := h.DOM.Find("td")
+		if dataCells.Length() == 0 {
+			return
+		}
+		row++
+
+		caseRefStr := normalizeSpace(dataCells.Eq(0).Text())
+		subject := normalizeSpace(dataCells.Eq(1).Text())
+		dateStr := normalizeSpace(dataCells.Eq(2).Text())
+		location := normalizeSpace(dataCells.Eq(3).Text())
+
+		if caseRefStr == "" || dateStr == "" {
+			return
+		}
+
+		caseRefs, err := ParseCaseRefs(caseRefStr)
+		if err != nil {
+			scrapeErr.Rows = append(scrapeErr.Rows, RowError{Row: row, Err: err})
+		}
+		if len(caseRefs) == 0 {
+			return
+		}
+
+		date, err := parseHearingDate(dateStr)
+		if err != nil {
+			scrapeErr.Rows = append(scrapeErr.Rows, RowError{Row: row, Err: err})
+			return
+		}
+
+		hearings = append(hearings, AnnouncedHearing{
+			Refs:     caseRefs,
+			Subject:  subject,
+			Date:     date,
+			Location: location,
+		})
+	})
+
+	if err := collector.Visit(pageURL); err != nil {
+		return nil, fmt.Errorf("visiting hearings page: %w", err)
+	}
+
+	if len(scrapeErr.Rows) > 0 {
+		return hearings, scrapeErr
+	}
+	return hearings, nil
+}
+
+func parseHearingDate(s string) (time.Time, error) {
+	match := hearingDateRegex.FindStringSubmatch(s)
+	if match == nil {
+		return time.Time{}, fmt.Errorf("no hearing date in %q", s)
+	}
+
+	date, err := monday.ParseInLocation(monday.DefaultFormatDeDELong, match[1], CourtLocation(), monday.LocaleDeDE)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing hearing date %q: %w", strings.TrimSpace(s), err)
+	}
+
+	if match[2] != "" {
+		hour, _ := strconv.Atoi(match[2])
+		minute, _ := strconv.Atoi(match[3])
+		date = time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, date.Location())
+	}
+
+	return date, nil
+}
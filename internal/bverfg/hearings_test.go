@@ -0,0 +1,36 @@
+package bverfg_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jgraeger/bverfgbot/internal/bverfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUpcomingHearings(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/hearings.html")
+	}))
+	defer srv.Close()
+
+	client := &bverfg.Client{BaseURL: srv.URL}
+	hearings, err := client.GetUpcomingHearings(context.Background())
+
+	var scrapeErr *bverfg.ScrapeError
+	if assert.ErrorAs(t, err, &scrapeErr) {
+		assert.Len(t, scrapeErr.Rows, 1)
+	}
+
+	if assert.Len(t, hearings, 2) {
+		assert.Equal(t, "2 BvF 1/22", hearings[0].RefString())
+		assert.Equal(t, "Zweites Nachtragshaushaltsgesetz 2021", hearings[0].Subject)
+		assert.Equal(t, "2023-06-21 10:00", hearings[0].Date.Format("2006-01-02 15:04"))
+		assert.Equal(t, "Sitzungssaal des Bundesverfassungsgerichts, Karlsruhe", hearings[0].Location)
+
+		assert.Equal(t, "1 BvR 1/23, 1 BvR 2/23", hearings[1].RefString())
+		assert.Equal(t, "2023-07-04 00:00", hearings[1].Date.Format("2006-01-02 15:04"))
+	}
+}
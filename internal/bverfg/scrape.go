@@ -1,8 +1,8 @@
 package bverfg
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
@@ -13,7 +13,7 @@ import (
 const (
 	bverfgDomain = "bundesverfassungsgericht.de"
 
-	senateDecisionsURL = "https://www.bundesverfassungsgericht.de/DE/Presse/Senatsbeschl%C3%BCsse/Senatsbeschl%C3%BCsse_node.html"
+	senateDecisionsPath = "/DE/Presse/Senatsbeschl%C3%BCsse/Senatsbeschl%C3%BCsse_node.html"
 
 	upcomingDecisionAllocationSize = 5
 )
@@ -21,63 +21,84 @@ const (
 // AnnouncedDecision is a court decision that's release has been announced, but
 // is not published yet.
 type AnnouncedDecision struct {
-	Ref         CaseReference
+	Refs        []CaseReference
 	Description string
 	// PublishDate is holds the timestamp with the announced publish date
 	PublishDate time.Time
 }
 
-func GetUpcomingSenateDecisions() []AnnouncedDecision {
+// GetUpcomingSenateDecisions scrapes the announced Senate decisions using
+// the DefaultClient.
+func GetUpcomingSenateDecisions(ctx context.Context) ([]AnnouncedDecision, error) {
+	return DefaultClient.GetUpcomingSenateDecisions(ctx)
+}
+
+// GetUpcomingSenateDecisions scrapes the announced Senate decisions. Rows
+// that cannot be parsed are skipped and reported by a *ScrapeError.
+func (c *Client) GetUpcomingSenateDecisions(ctx context.Context) ([]AnnouncedDecision, error) {
 	upcomingDecisions := make([]AnnouncedDecision, 0, upcomingDecisionAllocationSize)
 
-	c := colly.NewCollector(colly.AllowedDomains(bverfgDomain, fmt.Sprintf("www.%s", bverfgDomain)))
+	collector, err := c.newCollector(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	c.OnRequest(func(r *colly.Request) {
-		log.Println("scraping", r.URL.String())
-	})
+	pageURL := c.baseURL() + senateDecisionsPath
+	scrapeErr := &ScrapeError{URL: pageURL}
+	row := 0
 
-	c.OnHTML(`table[class="MsoNormalTable"] tr`, func(h *colly.HTMLElement) {
+	collector.OnHTML(`table[class="MsoNormalTable"] tr`, func(h *colly.HTMLElement) {
 		// We don't want th cells possibly included here
 		dataCells := h.DOM.Find("td")
 		if dataCells.Length() == 0 {
 			return
 		}
+		row++
 
-		// Only take the first case ref if multiple (though if never seens this on this page)
-		caseRefStr := dataCells.Eq(0).Text()
-		description := dataCells.Eq(1).Text()
-		dateStr := dataCells.Eq(2).Text()
+		caseRefStr := strings.TrimSpace(dataCells.Eq(0).Text())
+		description := strings.TrimSpace(dataCells.Eq(1).Text())
+		dateStr := strings.TrimSpace(dataCells.Eq(2).Text())
 
 		if caseRefStr == "" || description == "" || dateStr == "" {
 			return
 		}
 
-		// Only take the first case ref if multiple (though i've never seen this on this page yet)
-		caseRefStr = strings.Split(caseRefStr, ",")[0]
-		caseRef, err := ParseCaseRef(caseRefStr)
+		caseRefs, err := ParseCaseRefs(caseRefStr)
 		if err != nil {
-			log.Printf("error parsing scraped caseref %v: %v", caseRefStr, err)
+			scrapeErr.Rows = append(scrapeErr.Rows, RowError{Row: row, Err: err})
+		}
+		if len(caseRefs) == 0 {
 			return
 		}
 
 		// Parse german date string
-		loc, err := time.LoadLocation("Europe/Berlin")
+		date, err := monday.ParseInLocation(monday.DefaultFormatDeDELong, dateStr, CourtLocation(), monday.LocaleDeDE)
 		if err != nil {
-			log.Panicln("error loading fixed timezone location:", err)
-		}
-		date, err := monday.ParseInLocation(monday.DefaultFormatDeDELong, dateStr, loc, monday.LocaleDeDE)
-		if err != nil {
-			log.Printf("error parsing local date str %v: %v", dateStr, err)
+			scrapeErr.Rows = append(scrapeErr.Rows, RowError{
+				Row: row,
+				Err: fmt.Errorf("parsing publish date %q: %w", dateStr, err),
+			})
+			return
 		}
 
 		upcomingDecisions = append(upcomingDecisions, AnnouncedDecision{
-			Ref:         caseRef,
+			Refs:        caseRefs,
 			Description: description,
 			PublishDate: date,
 		})
 	})
 
-	c.Visit(senateDecisionsURL)
+	if err := collector.Visit(pageURL); err != nil {
+		return nil, fmt.Errorf("visiting senate decisions page: %w", err)
+	}
+
+	if len(scrapeErr.Rows) > 0 {
+		return upcomingDecisions, scrapeErr
+	}
+	return upcomingDecisions, nil
+}
 
-	return upcomingDecisions
+// RefString returns the case references of the decision separated by comma.
+func (d AnnouncedDecision) RefString() string {
+	return JoinCaseRefs(d.Refs)
 }
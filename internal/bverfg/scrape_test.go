@@ -0,0 +1,50 @@
+package bverfg_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jgraeger/bverfgbot/internal/bverfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUpcomingSenateDecisions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/senate_decisions.html")
+	}))
+	defer srv.Close()
+
+	client := &bverfg.Client{HTTPClient: srv.Client(), BaseURL: srv.URL}
+	upcoming, err := client.GetUpcomingSenateDecisions(context.Background())
+
+	var scrapeErr *bverfg.ScrapeError
+	if assert.ErrorAs(t, err, &scrapeErr) {
+		assert.Len(t, scrapeErr.Rows, 1)
+		assert.Equal(t, 2, scrapeErr.Rows[0].Row)
+	}
+
+	if assert.Len(t, upcoming, 2) {
+		assert.Equal(t, "1 BvR 2649/21, 1 BvR 2650/21", upcoming[0].RefString())
+		assert.Equal(t, "Einrichtungsbezogene Impfpflicht", upcoming[0].Description)
+		assert.Equal(t, "2022-05-19", upcoming[0].PublishDate.Format("2006-01-02"))
+		assert.Equal(t, "2 BvF 1/22", upcoming[1].RefString())
+	}
+}
+
+func TestGetUpcomingSenateDecisionsCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	client := &bverfg.Client{BaseURL: srv.URL}
+	_, err := client.GetUpcomingSenateDecisions(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+}
@@ -1,15 +1,22 @@
 package bverfg
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 )
 
 const (
 	// foundingYear holds a two digit year number in which the BVerfG established.
 	// used to parse case reference strings for the y2k wrap.
 	foundingYear = 51
+
+	// maxCaseRefRange limits the number of references a range like
+	// "1 BvR 1/20 - 3/20" is expanded to.
+	maxCaseRefRange = 100
 )
 
 type ProcedureType string
@@ -39,10 +46,36 @@ const (
 	Verzoegerungsruege              = ProcedureType("Vz")
 )
 
+// procedureTypes are all registers of the court.
+var procedureTypes = []ProcedureType{
+	Art18GG, Parteiverbotsverfahren, Wahlpruefungsbeschwerde, Praesidentenanklage,
+	Organstreit, AbstrakteNormenkontrolle, BundLaenderStreit, OeffentlichRechtlich,
+	Richteranklage, LandesverfassungsStreitigkeit, KonkreteNormenkontrolle,
+	Voelkerrechtsbindung, Divergenzvorlage, VorkonstitutionelleFortgeltung,
+	BundesgesetzlichesVerfahren, EinstweiligeAnordnung, Verfassungsbeschwerde,
+	SonstigesVerfahren, Dienstunfaehigkeitsfeststellung, Plenarentscheidung,
+	Prozesskostenhilfe, Verzoegerungsruege,
+}
+
 func (p ProcedureType) RefSign() string {
 	return string(p)
 }
 
+// Known reports whether p is a register of the court.
+func (p ProcedureType) Known() bool {
+	return p.String() != ""
+}
+
+// SenateBound reports whether references of the register start with the
+// number of the senate, unlike the plenary and special registers.
+func (p ProcedureType) SenateBound() bool {
+	switch p {
+	case Dienstunfaehigkeitsfeststellung, Plenarentscheidung, Prozesskostenhilfe, Verzoegerungsruege:
+		return false
+	}
+	return p.Known()
+}
+
 func (p ProcedureType) String() string {
 	switch p {
 	case Art18GG:
@@ -101,17 +134,43 @@ type CaseReference struct {
 	RunningNumber uint
 }
 
-var caseRefRegex *regexp.Regexp
+var (
+	caseRefRegex       *regexp.Regexp
+	strictCaseRefRegex *regexp.Regexp
+	// fullCaseRefRegex and shortCaseRefRegex match a single element of a
+	// list of references, where "2650/21" continues the register of the
+	// preceding reference.
+	fullCaseRefRegex  *regexp.Regexp
+	shortCaseRefRegex *regexp.Regexp
+	// caseRefListRegex matches lists like "1 BvR 2649/21, 2650/21 u.a."
+	caseRefListRegex  *regexp.Regexp
+	caseRefSepRegex   *regexp.Regexp
+	caseRefRangeRegex *regexp.Regexp
+	othersRegex       *regexp.Regexp
+)
 
 func init() {
 	caseRefRegex = regexp.MustCompile(`(1|2)\s([A-Za-z]*)\s(\d*)\/(\d{2})`)
+	strictCaseRefRegex = regexp.MustCompile(`^(?:(\d)\s+)?([A-Za-z]+)\s+(\d+)\s*/\s*(\d{2})$`)
+	fullCaseRefRegex = regexp.MustCompile(`^(1|2)\s+([A-Za-z]+)\s+(\d+)\s*/\s*(\d{2})$`)
+	shortCaseRefRegex = regexp.MustCompile(`^(\d+)\s*/\s*(\d{2})$`)
+	caseRefListRegex = regexp.MustCompile(
+		`[12]\s[A-Za-z]+\s\d+/\d{2}(?:\s*(?:,|;|-|–|bis|und)\s*(?:[12]\s[A-Za-z]+\s)?\d+/\d{2})*(?:\s*u\.\s?a\.)?`,
+	)
+	caseRefSepRegex = regexp.MustCompile(`\s*(?:,|;|\bund\b)\s*`)
+	caseRefRangeRegex = regexp.MustCompile(`\s*(?:-|–|\bbis\b)\s*`)
+	othersRegex = regexp.MustCompile(`\bu\.\s?a\.`)
 }
 
 func (c CaseReference) String() string {
 	twoDigitYear := c.Year % 100
 
+	if c.Senate == 0 {
+		return fmt.Sprintf("%v %d/%02d", c.Type.RefSign(), c.RunningNumber, twoDigitYear)
+	}
+
 	return fmt.Sprintf(
-		"%d %v %d/%d",
+		"%d %v %d/%02d",
 		c.Senate,
 		c.Type.RefSign(),
 		c.RunningNumber,
@@ -120,13 +179,184 @@ func (c CaseReference) String() string {
 }
 
 func ParseCaseRef(r string) (CaseReference, error) {
-	ref := CaseReference{}
-
 	match := caseRefRegex.FindStringSubmatch(r)
 	if len(match) != 5 {
-		return ref, fmt.Errorf("invalid case ref match: %v", match)
+		return CaseReference{}, fmt.Errorf("invalid case ref match: %v", match)
+	}
+
+	return parseCaseRefMatch(match)
+}
+
+var (
+	ErrInvalidCaseRef  = errors.New("invalid case ref")
+	ErrUnknownRegister = errors.New("unknown register")
+	ErrInvalidSenate   = errors.New("invalid senate")
+	ErrInvalidYear     = errors.New("invalid year")
+)
+
+// ParseCaseRefStrict parses a single case reference like "1 BvR 205/58",
+// or "PBvU 1/11" for the registers not bound to a senate. Unlike
+// ParseCaseRef, the whole string has to be the reference and its register
+// has to be known.
+func ParseCaseRefStrict(r string) (CaseReference, error) {
+	match := strictCaseRefRegex.FindStringSubmatch(strings.TrimSpace(r))
+	if match == nil {
+		return CaseReference{}, fmt.Errorf("%w: %q", ErrInvalidCaseRef, r)
+	}
+
+	ref := CaseReference{Type: ProcedureType(match[2])}
+	if !ref.Type.Known() {
+		return CaseReference{}, fmt.Errorf("%w: %v", ErrUnknownRegister, match[2])
+	}
+
+	switch {
+	case ref.Type.SenateBound() && match[1] != "1" && match[1] != "2":
+		return CaseReference{}, fmt.Errorf("%w: %q for register %v", ErrInvalidSenate, match[1], ref.Type)
+	case !ref.Type.SenateBound() && match[1] != "":
+		return CaseReference{}, fmt.Errorf("%w: register %v has no senate", ErrInvalidSenate, ref.Type)
+	case match[1] != "":
+		ref.Senate = match[1][0] - '0'
+	}
+
+	runningNumber, err := strconv.Atoi(match[3])
+	if err != nil || runningNumber == 0 {
+		return CaseReference{}, fmt.Errorf("%w: running number %v", ErrInvalidCaseRef, match[3])
+	}
+	ref.RunningNumber = uint(runningNumber)
+
+	ref.Year = expandYear(match[4])
+	if ref.Year > time.Now().Year() {
+		return CaseReference{}, fmt.Errorf("%w: %v lies in the future", ErrInvalidYear, ref.Year)
 	}
 
+	return ref, nil
+}
+
+// CaseRefsError is returned by ParseCaseRefs for the parts of a string
+// that could not be parsed as case references.
+type CaseRefsError struct {
+	Failed []string
+}
+
+func (e *CaseRefsError) Error() string {
+	return fmt.Sprintf("invalid case refs: %q", e.Failed)
+}
+
+// ParseCaseRefs parses a list of case references like
+// "1 BvR 2649/21, 2650/21 u.a." or ranges like "2 BvE 1/19 - 3/19".
+// Elements without senate and register continue the preceding reference.
+// The references that could be parsed are returned even if others failed.
+func ParseCaseRefs(r string) ([]CaseReference, error) {
+	var (
+		refs   []CaseReference
+		failed []string
+		prev   *CaseReference
+	)
+	seen := make(map[CaseReference]bool)
+
+	r = othersRegex.ReplaceAllString(r, "")
+	for _, part := range caseRefSepRegex.Split(strings.TrimSpace(r), -1) {
+		if part == "" {
+			continue
+		}
+
+		parsed, err := parseCaseRefPart(part, prev)
+		if err != nil {
+			failed = append(failed, part)
+			continue
+		}
+
+		for _, ref := range parsed {
+			if !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+		prev = &parsed[len(parsed)-1]
+	}
+
+	if len(failed) > 0 {
+		return refs, &CaseRefsError{Failed: failed}
+	}
+	return refs, nil
+}
+
+// parseCaseRefPart parses a single reference or a range of references.
+func parseCaseRefPart(part string, prev *CaseReference) ([]CaseReference, error) {
+	bounds := caseRefRangeRegex.Split(part, -1)
+	if len(bounds) > 2 {
+		return nil, fmt.Errorf("invalid case ref range: %v", part)
+	}
+
+	from, err := parseCaseRefElement(bounds[0], prev)
+	if err != nil {
+		return nil, err
+	}
+	if len(bounds) == 1 {
+		return []CaseReference{from}, nil
+	}
+
+	to, err := parseCaseRefElement(bounds[1], &from)
+	if err != nil {
+		return nil, err
+	}
+	if to.Senate != from.Senate || to.Type != from.Type || to.Year != from.Year ||
+		to.RunningNumber < from.RunningNumber || to.RunningNumber-from.RunningNumber >= maxCaseRefRange {
+		return nil, fmt.Errorf("invalid case ref range: %v", part)
+	}
+
+	refs := make([]CaseReference, 0, to.RunningNumber-from.RunningNumber+1)
+	for n := from.RunningNumber; n <= to.RunningNumber; n++ {
+		ref := from
+		ref.RunningNumber = n
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+func parseCaseRefElement(element string, prev *CaseReference) (CaseReference, error) {
+	if match := fullCaseRefRegex.FindStringSubmatch(element); match != nil {
+		return parseCaseRefMatch(match)
+	}
+
+	match := shortCaseRefRegex.FindStringSubmatch(element)
+	if match == nil || prev == nil {
+		return CaseReference{}, fmt.Errorf("invalid case ref: %v", element)
+	}
+	return parseCaseRefMatch([]string{match[0], strconv.Itoa(int(prev.Senate)), string(prev.Type), match[1], match[2]})
+}
+
+// FindCaseRefs returns all distinct case references mentioned in a text,
+// e.g. the title of a decision or the body of a press release.
+func FindCaseRefs(text string) []CaseReference {
+	var refs []CaseReference
+	seen := make(map[CaseReference]bool)
+	for _, list := range caseRefListRegex.FindAllString(text, -1) {
+		// Keep what could be parsed of malformed lists
+		parsed, _ := ParseCaseRefs(list)
+		for _, ref := range parsed {
+			if !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+
+	return refs
+}
+
+// JoinCaseRefs formats a list of case references separated by comma.
+func JoinCaseRefs(refs []CaseReference) string {
+	formatted := make([]string, len(refs))
+	for i, ref := range refs {
+		formatted[i] = ref.String()
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func parseCaseRefMatch(match []string) (CaseReference, error) {
+	ref := CaseReference{}
+
 	senate, err := strconv.Atoi(match[1])
 	if err != nil {
 		return ref, fmt.Errorf("parse as senate number: %v", match[1])
@@ -141,22 +371,19 @@ func ParseCaseRef(r string) (CaseReference, error) {
 	}
 	ref.RunningNumber = uint(runningNumber)
 
-	year, err := strconv.Atoi(match[4])
-	if err != nil {
+	if _, err := strconv.Atoi(match[4]); err != nil {
 		return ref, fmt.Errorf("parse as two digit year: %v", match[4])
 	}
-	if year >= foundingYear {
-		ref.Year = year + 1900
-	} else {
-		ref.Year = year + 2000
-	}
+	ref.Year = expandYear(match[4])
 
 	return ref, nil
 }
 
-type Decision struct {
-	Ref         CaseReference
-	title       string
-	link        string
-	description string
+// expandYear expands a two digit year, which has to be numeric.
+func expandYear(twoDigitYear string) int {
+	year, _ := strconv.Atoi(twoDigitYear)
+	if year >= foundingYear {
+		return year + 1900
+	}
+	return year + 2000
 }
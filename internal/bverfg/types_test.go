@@ -97,6 +97,15 @@ func TestCaseRefString(t *testing.T) {
 			},
 			expected: "2 BvO 3/56",
 		},
+		{
+			name: "Test plenary decision",
+			input: bverfg.CaseReference{
+				Type:          bverfg.Plenarentscheidung,
+				RunningNumber: 1,
+				Year:          2005,
+			},
+			expected: "PBvU 1/05",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -108,3 +117,156 @@ func TestCaseRefString(t *testing.T) {
 	}
 
 }
+
+func TestFindCaseRefs(t *testing.T) {
+	text := "Beschluss vom 1. Januar 2023 - 1 BvR 205/58, 2 BvB 1/13 - (verbunden mit 1 BvR 205/58)"
+
+	refs := bverfg.FindCaseRefs(text)
+	assert.Equal(t, []bverfg.CaseReference{
+		{Senate: 1, Type: bverfg.Verfassungsbeschwerde, RunningNumber: 205, Year: 1958},
+		{Senate: 2, Type: bverfg.Parteiverbotsverfahren, RunningNumber: 1, Year: 2013},
+	}, refs)
+
+	assert.Equal(t, []bverfg.CaseReference{
+		{Senate: 1, Type: bverfg.Verfassungsbeschwerde, RunningNumber: 2649, Year: 2021},
+		{Senate: 1, Type: bverfg.Verfassungsbeschwerde, RunningNumber: 2650, Year: 2021},
+	}, bverfg.FindCaseRefs("Beschluss vom 1. März 2023 - 1 BvR 2649/21, 2650/21 u.a. -"))
+
+	assert.Empty(t, bverfg.FindCaseRefs("Keine Aktenzeichen"))
+}
+
+func TestParseCaseRefs(t *testing.T) {
+	bvr := func(n uint, year int) bverfg.CaseReference {
+		return bverfg.CaseReference{Senate: 1, Type: bverfg.Verfassungsbeschwerde, RunningNumber: n, Year: year}
+	}
+
+	testCases := []struct {
+		name         string
+		input        string
+		expectedRefs []bverfg.CaseReference
+		failed       []string
+	}{
+		{
+			name:         "Parse joined proceedings",
+			input:        "1 BvR 2649/21, 1 BvR 2650/21 u.a.",
+			expectedRefs: []bverfg.CaseReference{bvr(2649, 2021), bvr(2650, 2021)},
+		},
+		{
+			name:         "Parse abbreviated references",
+			input:        "1 BvR 2649/21, 2650/21 und 12/22",
+			expectedRefs: []bverfg.CaseReference{bvr(2649, 2021), bvr(2650, 2021), bvr(12, 2022)},
+		},
+		{
+			name:         "Parse range",
+			input:        "1 BvR 1/20 - 3/20",
+			expectedRefs: []bverfg.CaseReference{bvr(1, 2020), bvr(2, 2020), bvr(3, 2020)},
+		},
+		{
+			name:         "Parse range spelled out",
+			input:        "1 BvR 1/20 bis 1 BvR 2/20",
+			expectedRefs: []bverfg.CaseReference{bvr(1, 2020), bvr(2, 2020)},
+		},
+		{
+			name:         "Report failed parts",
+			input:        "1 BvR 1/20, foo, 3/19 - 1/19",
+			expectedRefs: []bverfg.CaseReference{bvr(1, 2020)},
+			failed:       []string{"foo", "3/19 - 1/19"},
+		},
+		{
+			name:   "Fail abbreviated reference without predecessor",
+			input:  "2650/21",
+			failed: []string{"2650/21"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			refs, err := bverfg.ParseCaseRefs(tc.input)
+			assert.Equal(t, tc.expectedRefs, refs)
+			if tc.failed == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var refsErr *bverfg.CaseRefsError
+			if assert.ErrorAs(t, err, &refsErr) {
+				assert.Equal(t, tc.failed, refsErr.Failed)
+			}
+		})
+	}
+}
+
+func TestParseCaseRefStrict(t *testing.T) {
+	testCases := []struct {
+		name        string
+		input       string
+		expectedErr error
+		expectedRef bverfg.CaseReference
+	}{
+		{
+			name:  "Parse valid case ref",
+			input: " 1 BvR 205/58 ",
+			expectedRef: bverfg.CaseReference{
+				Senate:        1,
+				Type:          bverfg.Verfassungsbeschwerde,
+				RunningNumber: 205,
+				Year:          1958,
+			},
+		},
+		{
+			name:  "Parse plenary case ref",
+			input: "PBvU 1/11",
+			expectedRef: bverfg.CaseReference{
+				Type:          bverfg.Plenarentscheidung,
+				RunningNumber: 1,
+				Year:          2011,
+			},
+		},
+		{
+			name:        "Error with unknown register",
+			input:       "1 BvX 1/20",
+			expectedErr: bverfg.ErrUnknownRegister,
+		},
+		{
+			name:        "Error with invalid senate",
+			input:       "3 BvR 3/19",
+			expectedErr: bverfg.ErrInvalidSenate,
+		},
+		{
+			name:        "Error with missing senate",
+			input:       "BvR 3/19",
+			expectedErr: bverfg.ErrInvalidSenate,
+		},
+		{
+			name:        "Error with senate for plenary register",
+			input:       "1 PBvU 1/11",
+			expectedErr: bverfg.ErrInvalidSenate,
+		},
+		{
+			name:        "Error with future year",
+			input:       "1 BvR 3/50",
+			expectedErr: bverfg.ErrInvalidYear,
+		},
+		{
+			name:        "Error with missing running number",
+			input:       "1 BvR /19",
+			expectedErr: bverfg.ErrInvalidCaseRef,
+		},
+		{
+			name:        "Error with trailing text",
+			input:       "1 BvR 3/19 u.a.",
+			expectedErr: bverfg.ErrInvalidCaseRef,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			parsed, err := bverfg.ParseCaseRefStrict(tc.input)
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expectedRef, parsed)
+		})
+	}
+}
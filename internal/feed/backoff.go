@@ -0,0 +1,157 @@
+package feed
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// BackoffPolicy describes how long a Feed waits between fetches after
+// consecutive failures.
+type BackoffPolicy struct {
+	// Base is the delay after the first failure, doubled for every
+	// further consecutive failure.
+	Base time.Duration
+	// Max caps the delay between two fetches.
+	Max time.Duration
+	// Jitter randomizes the delay by up to the given fraction (0-1) of it.
+	Jitter float64
+	// OpenAfter is the number of consecutive failures after which the
+	// circuit opens.
+	OpenAfter int
+}
+
+// DefaultBackoffPolicy is used by feeds that got no other policy set.
+var DefaultBackoffPolicy = BackoffPolicy{
+	Base:      10 * time.Second,
+	Max:       15 * time.Minute,
+	Jitter:    0.2,
+	OpenAfter: 5,
+}
+
+// withDefaults fills in the unset fields from DefaultBackoffPolicy, so
+// failing feeds are never fetched without any delay.
+func (p BackoffPolicy) withDefaults() BackoffPolicy {
+	if p.Base <= 0 {
+		p.Base = DefaultBackoffPolicy.Base
+	}
+	if p.Max <= 0 {
+		p.Max = DefaultBackoffPolicy.Max
+	}
+	if p.Max < p.Base {
+		p.Max = p.Base
+	}
+	if p.Jitter < 0 {
+		p.Jitter = 0
+	} else if p.Jitter > 1 {
+		p.Jitter = 1
+	}
+	if p.OpenAfter <= 0 {
+		p.OpenAfter = DefaultBackoffPolicy.OpenAfter
+	}
+	return p
+}
+
+// Delay returns the delay after the given number of consecutive failures.
+func (p BackoffPolicy) Delay(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+
+	d := p.Base
+	for i := 1; i < failures && d < p.Max; i++ {
+		d *= 2
+	}
+	if d > p.Max {
+		d = p.Max
+	}
+
+	if p.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(d))
+	}
+
+	return d
+}
+
+// CircuitState is the state of the circuit breaker guarding a Feed.
+type CircuitState int
+
+const (
+	// CircuitClosed means the feed is healthy.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen means the feed failed too often in a row and is only
+	// probed at the maximum backoff delay.
+	CircuitOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	}
+
+	return ""
+}
+
+// Health is a snapshot of the fetch health of a Feed.
+type Health struct {
+	State               CircuitState
+	ConsecutiveFailures int
+	// FailingSince is the time of the first of the consecutive failures.
+	FailingSince time.Time
+	LastError    error
+}
+
+// health tracks consecutive fetch failures of a feed.
+type health struct {
+	mu     sync.RWMutex
+	policy BackoffPolicy
+	Health
+}
+
+// recordSuccess resets the failures and reports whether the circuit closed.
+func (h *health) recordSuccess() (closed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	closed = h.State == CircuitOpen
+	h.Health = Health{}
+	return closed
+}
+
+// recordFailure counts the failure and reports whether the circuit opened.
+func (h *health) recordFailure(err error) (opened bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.ConsecutiveFailures == 0 {
+		h.FailingSince = time.Now()
+	}
+	h.ConsecutiveFailures++
+	h.LastError = err
+
+	if h.State == CircuitClosed && h.ConsecutiveFailures >= h.policy.OpenAfter {
+		h.State = CircuitOpen
+		return true
+	}
+	return false
+}
+
+func (h *health) snapshot() Health {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.Health
+}
+
+// delay returns the backoff delay for the current failures.
+func (h *health) delay() time.Duration {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.State == CircuitOpen {
+		return h.policy.Max
+	}
+	return h.policy.Delay(h.ConsecutiveFailures)
+}
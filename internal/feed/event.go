@@ -0,0 +1,54 @@
+package feed
+
+import "github.com/mmcdole/gofeed"
+
+// Event is published to the subscribers of a Feed.
+type Event interface {
+	isEvent()
+}
+
+// NewItemsEvent is published whenever a fetch discovered items that
+// haven't been seen before.
+type NewItemsEvent struct {
+	// Feed is the complete feed document the items were taken from.
+	Feed *gofeed.Feed
+	// Items holds only the newly discovered items, in document order.
+	Items []*gofeed.Item
+	// Initial is true if the feed has never been seen before, meaning Items
+	// is the feed's backlog rather than news.
+	Initial bool
+
+	ack func()
+}
+
+func (NewItemsEvent) isEvent() {}
+
+// Ack marks the items as seen. Subscribers have to call it once the items
+// are handled or persisted, otherwise the items are published again after
+// a restart. With several subscribers, the first acknowledgement counts.
+func (e NewItemsEvent) Ack() {
+	if e.ack != nil {
+		e.ack()
+	}
+}
+
+// ItemID returns the identity of a feed item used for deduplication.
+// It is the item's GUID, falling back to the link and finally the title
+// for feeds that don't provide either.
+func ItemID(item *gofeed.Item) string {
+	if item.GUID != "" {
+		return item.GUID
+	}
+	if item.Link != "" {
+		return item.Link
+	}
+	return item.Title
+}
+
+// CircuitEvent is published whenever the circuit breaker of a feed changes
+// its state, e.g. to alert operators about a feed being down.
+type CircuitEvent struct {
+	Health Health
+}
+
+func (CircuitEvent) isEvent() {}
@@ -1,10 +1,8 @@
 package feed
 
 import (
-	"bytes"
 	"context"
-	"crypto/sha1"
-	"io"
+	"fmt"
 	"log"
 	"net/http"
 	"sync"
@@ -17,6 +15,9 @@ import (
 const (
 	fetchTimeout           = 10 * time.Second
 	defaultRefreshInterval = 5 * time.Second
+	// maxServerDelay caps the delay a server can request via Cache-Control or
+	// Retry-After, so a misconfigured server can't pause a feed for days.
+	maxServerDelay = 30 * time.Minute
 
 	rssTimestampFormat = "Mon, 2 Jan 2006 15:04:05 -0700"
 )
@@ -25,22 +26,37 @@ type Feed struct {
 	mu     sync.RWMutex
 	closed bool
 	ctx    context.Context
+	cancel context.CancelFunc // stops the fetch loop
 
 	url             string
 	http            http.Client
 	refreshInterval atomic.Duration
-	lastChecksum    []byte // SHA1 hash of the last feed processed to detect changes
+	store           Store     // keeps track of items already published
+	etag            string    // ETag of the last response, only accessed by the fetch loop
+	lastModified    string    // Last-Modified of the last response, only accessed by the fetch loop
+	notBefore       time.Time // earliest next fetch requested by the server, only accessed by the fetch loop
+	health          health
 	parser          *gofeed.Parser
-	subscriptions   []chan gofeed.Feed
+	subscriptions   []*Subscription
+	started         bool          // fetch loop has been started
+	loopDone        chan struct{} // closed when the fetch loop exited
+
+	inFlightMu sync.Mutex
+	inFlight   map[string]struct{} // IDs of published items not acknowledged yet
 }
 
 func NewFeed(ctx context.Context, url string) *Feed {
+	ctx, cancel := context.WithCancel(ctx)
 	feed := &Feed{
 		ctx:             ctx,
+		cancel:          cancel,
 		url:             url,
 		refreshInterval: *atomic.NewDuration(defaultRefreshInterval),
-		lastChecksum:    make([]byte, sha1.Size),
+		store:           NewMemoryStore(),
+		health:          health{policy: DefaultBackoffPolicy},
 		parser:          gofeed.NewParser(),
+		inFlight:        make(map[string]struct{}),
+		loopDone:        make(chan struct{}),
 	}
 
 	return feed
@@ -56,105 +72,353 @@ func (f *Feed) SetTranslator(t gofeed.Translator) {
 	f.parser.RSSTranslator = t
 }
 
-func (f *Feed) Subscribe() chan gofeed.Feed {
+// SetBackoff sets the policy used after consecutive fetch failures. Unset
+// fields of p are taken from DefaultBackoffPolicy.
+func (f *Feed) SetBackoff(p BackoffPolicy) {
+	f.health.mu.Lock()
+	defer f.health.mu.Unlock()
+	f.health.policy = p.withDefaults()
+}
+
+// Health returns the current fetch health of the feed.
+func (f *Feed) Health() Health {
+	return f.health.snapshot()
+}
+
+// SetStore replaces the in-memory store of seen items. It has to be called
+// before the first subscription.
+func (f *Feed) SetStore(s Store) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.store = s
+}
+
+// Subscribe subscribes to the feed using the DefaultSubscriptionConfig.
+func (f *Feed) Subscribe() *Subscription {
+	return f.SubscribeWithConfig(DefaultSubscriptionConfig)
+}
+
+// SubscribeWithConfig subscribes to the feed. The feed starts fetching on
+// the first subscription.
+func (f *Feed) SubscribeWithConfig(cfg SubscriptionConfig) *Subscription {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
+	s := newSubscription(f, cfg)
+	if f.closed {
+		s.close()
+		return s
+	}
+	f.subscriptions = append(f.subscriptions, s)
+
 	// Lazy start fetching loop on the first subscription
-	if len(f.subscriptions) == 0 {
-		defer func() {
-			go f.fetchLoop()
-		}()
+	if !f.started {
+		f.started = true
+		go f.fetchLoop()
 	}
 
-	ch := make(chan gofeed.Feed, 1)
-	f.subscriptions = append(f.subscriptions, ch)
-	return ch
+	return s
 }
 
-func (f *Feed) Close() {
+func (f *Feed) unsubscribe(s *Subscription) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
-	if !f.closed {
-		f.closed = true
-		for _, ch := range f.subscriptions {
-			close(ch)
+	for i, sub := range f.subscriptions {
+		if sub == s {
+			f.subscriptions = append(f.subscriptions[:i], f.subscriptions[i+1:]...)
+			return
 		}
 	}
 }
 
-func (f *Feed) publish(feed gofeed.Feed) {
+// Close stops fetching, closes all subscriptions and waits for the
+// fetch loop to exit.
+func (f *Feed) Close() {
+	f.shutdown()
+
 	f.mu.RLock()
-	defer f.mu.RUnlock()
+	started := f.started
+	f.mu.RUnlock()
+	if started {
+		<-f.loopDone
+	}
+}
+
+func (f *Feed) shutdown() {
+	// Cancel before locking to abort a running fetch holding the read lock
+	f.cancel()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
 
 	if f.closed {
 		return
 	}
+	f.closed = true
+	for _, s := range f.subscriptions {
+		s.close()
+	}
+	f.subscriptions = nil
+}
 
-	for _, ch := range f.subscriptions {
-		go func(ch chan gofeed.Feed) {
-			ch <- feed
-		}(ch)
+// publish delivers e to all subscriptions and returns once every delivery
+// succeeded or was given up according to the delivery policies. It returns
+// the number of subscriptions e was delivered to.
+func (f *Feed) publish(e Event) int {
+	f.mu.RLock()
+	subs := make([]*Subscription, len(f.subscriptions))
+	copy(subs, f.subscriptions)
+	f.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	delivered := atomic.NewInt64(0)
+	for _, s := range subs {
+		wg.Add(1)
+		go func(s *Subscription) {
+			defer wg.Done()
+			if s.deliver(e) {
+				delivered.Inc()
+			} else {
+				log.Printf("dropped %T of feed %v for slow subscriber", e, f.url)
+			}
+		}(s)
 	}
+	wg.Wait()
+
+	return int(delivered.Load())
 }
 
 func (f *Feed) fetchLoop() {
+	defer close(f.loopDone)
+	defer f.shutdown()
+
 	for {
 		select {
-		case <-time.After(f.refreshInterval.Load()):
-			f.fetchFeed()
+		case <-time.After(f.nextFetchDelay()):
+			newItems, v, err := f.fetchFeed()
+			if f.ctx.Err() != nil {
+				// Fetch got aborted by shutdown
+				continue
+			}
+			f.handleFetchResult(err)
+			if newItems != nil {
+				f.track(newItems)
+				// Items nobody received are published again with the next
+				// fetch, which mustn't be answered with 304 Not Modified
+				if f.publish(*newItems) == 0 {
+					f.release(newItems.Items)
+					continue
+				}
+			}
+			if v != nil {
+				f.etag, f.lastModified = v.etag, v.lastModified
+			}
 		case <-f.ctx.Done():
 			log.Println("shutdown feed:", f.url)
-			f.Close()
 			return
 		}
 	}
 }
 
-func (f *Feed) fetchFeed() {
+func (f *Feed) handleFetchResult(err error) {
+	if err == nil {
+		if f.health.recordSuccess() {
+			log.Printf("feed %v recovered", f.url)
+			f.publish(CircuitEvent{Health: f.Health()})
+		}
+		return
+	}
+
+	log.Printf("error fetching feed %v: %v", f.url, err)
+	if f.health.recordFailure(err) {
+		log.Printf("feed %v failed too often, opening circuit", f.url)
+		f.publish(CircuitEvent{Health: f.Health()})
+	}
+}
+
+// nextFetchDelay returns the refresh interval or the backoff delay after
+// failures, unless the server asked us to wait longer.
+func (f *Feed) nextFetchDelay() time.Duration {
+	d := f.refreshInterval.Load()
+	if f.Health().ConsecutiveFailures > 0 {
+		d = f.health.delay()
+	}
+	if wait := time.Until(f.notBefore); wait > d {
+		d = wait
+	}
+	return d
+}
+
+// updateNotBefore adapts the earliest next fetch to the Cache-Control
+// max-age and Retry-After headers of the response.
+func (f *Feed) updateNotBefore(res *http.Response) {
+	now := time.Now()
+
+	var d time.Duration
+	if age, ok := maxAge(res.Header); ok {
+		d = age
+	}
+	if retry, ok := retryAfter(res.Header, now); ok && retry > d {
+		d = retry
+	}
+	if d > maxServerDelay {
+		d = maxServerDelay
+	}
+
+	f.notBefore = now.Add(d)
+}
+
+// validators are the response headers identifying a version of the feed
+// document for conditional requests.
+type validators struct {
+	etag         string
+	lastModified string
+}
+
+// fetchFeed fetches the feed and returns an event holding the new items,
+// if there are any. The items are not tracked yet and the validators of
+// the processed document have to be remembered once they are published.
+func (f *Feed) fetchFeed() (*NewItemsEvent, *validators, error) {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 
-	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	ctx, cancel := context.WithTimeout(f.ctx, fetchTimeout)
 	defer cancel()
 
 	req, err := http.NewRequest("GET", f.url, nil)
 	if err != nil {
-		log.Printf("error creating request: %v", err)
-		return
+		return nil, nil, fmt.Errorf("creating request: %w", err)
+	}
+	if f.etag != "" {
+		req.Header.Set("If-None-Match", f.etag)
+	}
+	if f.lastModified != "" {
+		req.Header.Set("If-Modified-Since", f.lastModified)
 	}
 
 	res, err := f.http.Do(req.WithContext(ctx))
 	if err != nil {
-		log.Printf("error fetching feed: %v", err)
-		return
+		return nil, nil, err
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
-		log.Printf("unexpected status code while fetching feed %d", res.StatusCode)
-		return
+	f.updateNotBefore(res)
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unexpected status code %d", res.StatusCode)
 	}
 
-	content, err := io.ReadAll(res.Body)
+	feed, err := f.parser.Parse(res.Body)
 	if err != nil {
-		log.Printf("error reading feed body: %v", err)
-		return
+		return nil, nil, fmt.Errorf("parsing feed: %w", err)
 	}
 
-	hash := sha1.Sum(content)
-	if bytes.Equal(f.lastChecksum, hash[:]) {
-		// Feed are the same as last time
-		return
+	newItems, initial, err := f.filterNew(feed.Items)
+	if err != nil {
+		return nil, nil, fmt.Errorf("filtering new items: %w", err)
+	}
+	// Only remember the validators of documents we were able to process
+	v := &validators{
+		etag:         res.Header.Get("ETag"),
+		lastModified: res.Header.Get("Last-Modified"),
+	}
+
+	if len(newItems) == 0 {
+		// Nothing but cosmetic changes since the last fetch
+		return nil, v, nil
+	}
+
+	return &NewItemsEvent{Feed: feed, Items: newItems, Initial: initial}, v, nil
+}
+
+// track holds the items of e back from being published again until they
+// are acknowledged and lets e acknowledge them.
+func (f *Feed) track(e *NewItemsEvent) {
+	f.inFlightMu.Lock()
+	for _, item := range e.Items {
+		f.inFlight[ItemID(item)] = struct{}{}
+	}
+	f.inFlightMu.Unlock()
+
+	items := e.Items
+	var once sync.Once
+	e.ack = func() {
+		once.Do(func() {
+			f.markSeen(items)
+			f.release(items)
+		})
 	}
-	f.lastChecksum = hash[:]
+}
 
-	feed, err := f.parser.Parse(bytes.NewReader(content))
+// release allows publishing the items again, e.g. if they weren't delivered.
+func (f *Feed) release(items []*gofeed.Item) {
+	f.inFlightMu.Lock()
+	defer f.inFlightMu.Unlock()
+	for _, item := range items {
+		delete(f.inFlight, ItemID(item))
+	}
+}
+
+func (f *Feed) isInFlight(id string) bool {
+	f.inFlightMu.Lock()
+	defer f.inFlightMu.Unlock()
+	_, ok := f.inFlight[id]
+	return ok
+}
+
+func (f *Feed) markSeen(items []*gofeed.Item) {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = ItemID(item)
+	}
+
+	// Not bound to the feed context, so items acknowledged during shutdown are still recorded
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+	if err := f.store.MarkSeen(ctx, f.url, ids); err != nil {
+		log.Printf("error marking items of feed %v as seen: %v", f.url, err)
+	}
+}
+
+// filterNew returns the items that haven't been seen before and aren't
+// waiting for an acknowledgement. initial is true if no item of the feed has
+// ever been seen.
+func (f *Feed) filterNew(items []*gofeed.Item) (newItems []*gofeed.Item, initial bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	hasSeen, err := f.store.HasSeen(ctx, f.url)
 	if err != nil {
-		log.Printf("error parsing feed %v: %v\n", f.url, err)
-		return
+		return nil, false, err
+	}
+
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = ItemID(item)
+	}
+	unseenIDs, err := f.store.Unseen(ctx, f.url, ids)
+	if err != nil {
+		return nil, false, err
+	}
+
+	unseen := make(map[string]struct{}, len(unseenIDs))
+	for _, id := range unseenIDs {
+		unseen[id] = struct{}{}
+	}
+	for _, item := range items {
+		id := ItemID(item)
+		if _, ok := unseen[id]; !ok || f.isInFlight(id) {
+			continue
+		}
+		// Don't publish items listed twice in the same document
+		delete(unseen, id)
+		newItems = append(newItems, item)
 	}
 
-	go f.publish(*feed)
+	return newItems, !hasSeen, nil
 }
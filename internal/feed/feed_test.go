@@ -8,12 +8,15 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"runtime"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-faker/faker/v4"
 	"github.com/jgraeger/bverfgbot/internal/feed"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
 )
 
 const (
@@ -33,12 +36,13 @@ func TestFeed(t *testing.T) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	feed := feed.NewFeed(ctx, srv.server.URL)
-	feed.SetRefreshInterval(2 * time.Millisecond)
-	recvCh := feed.Subscribe()
+	f := feed.NewFeed(ctx, srv.server.URL)
+	f.SetRefreshInterval(2 * time.Millisecond)
+	recvCh := f.Subscribe().Events()
 
 	// Read initial feed
-	<-recvCh
+	e := <-recvCh
+	assert.Len(t, e.(feed.NewItemsEvent).Items, numTestFeedItems)
 
 	// No new data if no refresh
 	select {
@@ -50,13 +54,396 @@ func TestFeed(t *testing.T) {
 
 	srv.rotateFeed()
 	select {
+	case e := <-recvCh:
+		assert.Len(t, e.(feed.NewItemsEvent).Items, numTestFeedItems)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("didn't receive new feed data after update")
+	}
+}
+
+func TestFeedDeduplication(t *testing.T) {
+	srv, err := NewFakeServer(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := feed.NewFeed(ctx, srv.server.URL)
+	f.SetRefreshInterval(2 * time.Millisecond)
+	recvCh := f.Subscribe().Events()
+
+	// Read initial feed
+	<-recvCh
+
+	// Reordered items and a new publish date are no news
+	srv.shuffleFeed()
+	select {
 	case <-recvCh:
+		t.Fatal("received event after cosmetic feed change")
+	case <-time.After(10 * time.Millisecond):
 		break
-	case <-time.After(5 * time.Millisecond):
+	}
+
+	// Two items arriving within the same poll are both published
+	if err := srv.addItems(2); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-recvCh:
+		newItems := e.(feed.NewItemsEvent).Items
+		if assert.Len(t, newItems, 2) {
+			srv.mu.RLock()
+			assert.Equal(t, srv.feedData.Items[0].Link, newItems[0].GUID)
+			assert.Equal(t, srv.feedData.Items[1].Link, newItems[1].GUID)
+			srv.mu.RUnlock()
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("didn't receive new items")
+	}
+}
+
+func TestFeedRestartWithStore(t *testing.T) {
+	srv, err := NewFakeServer(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := feed.NewMemoryStore()
+
+	f := feed.NewFeed(context.Background(), srv.server.URL)
+	f.SetRefreshInterval(2 * time.Millisecond)
+	f.SetStore(store)
+	e := (<-f.Subscribe().Events()).(feed.NewItemsEvent)
+	assert.True(t, e.Initial)
+	assert.Len(t, e.Items, numTestFeedItems)
+	e.Ack()
+	f.Close()
+
+	// Items published while the feed was down
+	if err := srv.addItems(1); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f = feed.NewFeed(ctx, srv.server.URL)
+	f.SetRefreshInterval(2 * time.Millisecond)
+	f.SetStore(store)
+	select {
+	case e := <-f.Subscribe().Events():
+		newItems := e.(feed.NewItemsEvent)
+		assert.False(t, newItems.Initial)
+		assert.Len(t, newItems.Items, 1)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("didn't receive item published during downtime")
+	}
+}
+
+func TestFeedRestartWithUnacknowledgedItems(t *testing.T) {
+	srv, err := NewFakeServer(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := feed.NewMemoryStore()
+
+	f := feed.NewFeed(context.Background(), srv.server.URL)
+	f.SetRefreshInterval(2 * time.Millisecond)
+	f.SetStore(store)
+	sub := f.Subscribe()
+	(<-sub.Events()).(feed.NewItemsEvent).Ack()
+
+	if err := srv.addItems(1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-sub.Events():
+		// Received but never acknowledged
+		assert.Len(t, e.(feed.NewItemsEvent).Items, 1)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("didn't receive new item")
+	}
+
+	// Not published again while waiting for the acknowledgement
+	select {
+	case <-sub.Events():
+		t.Fatal("unacknowledged item was published again")
+	case <-time.After(10 * time.Millisecond):
+	}
+	f.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f = feed.NewFeed(ctx, srv.server.URL)
+	f.SetRefreshInterval(2 * time.Millisecond)
+	f.SetStore(store)
+	select {
+	case e := <-f.Subscribe().Events():
+		newItems := e.(feed.NewItemsEvent)
+		assert.False(t, newItems.Initial)
+		assert.Len(t, newItems.Items, 1)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("didn't receive unacknowledged item after restart")
+	}
+}
+
+func TestFeedConditionalRequests(t *testing.T) {
+	srv, err := NewFakeServer(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.etags = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := feed.NewFeed(ctx, srv.server.URL)
+	f.SetRefreshInterval(2 * time.Millisecond)
+	recvCh := f.Subscribe().Events()
+
+	// Read initial feed
+	<-recvCh
+
+	<-time.After(10 * time.Millisecond)
+	assert.Positive(t, srv.notModified.Load(), "feed was fetched without If-None-Match")
+	assert.Equal(t, srv.requests.Load()-1, srv.notModified.Load())
+
+	srv.rotateFeed()
+	select {
+	case e := <-recvCh:
+		assert.Len(t, e.(feed.NewItemsEvent).Items, numTestFeedItems)
+	case <-time.After(100 * time.Millisecond):
 		t.Fatal("didn't receive new feed data after update")
 	}
 }
 
+func TestFeedConditionalRequestsAfterDroppedItems(t *testing.T) {
+	srv, err := NewFakeServer(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.etags = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := feed.NewFeed(ctx, srv.server.URL)
+	f.SetRefreshInterval(2 * time.Millisecond)
+	// Never reads a single event, so every item gets dropped
+	dropping := f.SubscribeWithConfig(feed.SubscriptionConfig{Policy: feed.BlockWithTimeout, Timeout: time.Millisecond})
+
+	<-time.After(20 * time.Millisecond)
+	assert.Zero(t, srv.notModified.Load(), "undelivered document was answered with 304")
+
+	// The items are published again to the next subscriber
+	dropping.Unsubscribe()
+	select {
+	case e := <-f.Subscribe().Events():
+		assert.Len(t, e.(feed.NewItemsEvent).Items, numTestFeedItems)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("didn't receive dropped items")
+	}
+}
+
+func TestFeedHonoursMaxAge(t *testing.T) {
+	srv, err := NewFakeServer(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.header.Set("Cache-Control", "public, max-age=60")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := feed.NewFeed(ctx, srv.server.URL)
+	f.SetRefreshInterval(2 * time.Millisecond)
+	<-f.Subscribe().Events()
+
+	<-time.After(20 * time.Millisecond)
+	assert.Equal(t, int64(1), srv.requests.Load())
+}
+
+func TestFeedCircuitBreaker(t *testing.T) {
+	srv, err := NewFakeServer(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.status = http.StatusInternalServerError
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := feed.NewFeed(ctx, srv.server.URL)
+	f.SetRefreshInterval(time.Millisecond)
+	f.SetBackoff(feed.BackoffPolicy{Base: time.Millisecond, Max: 2 * time.Millisecond, OpenAfter: 3})
+	recvCh := f.Subscribe().Events()
+
+	select {
+	case e := <-recvCh:
+		health := e.(feed.CircuitEvent).Health
+		assert.Equal(t, feed.CircuitOpen, health.State)
+		assert.GreaterOrEqual(t, health.ConsecutiveFailures, 3)
+		assert.Error(t, health.LastError)
+		assert.False(t, health.FailingSince.IsZero())
+	case <-time.After(50 * time.Millisecond):
+		t.Fatal("circuit didn't open")
+	}
+	assert.Equal(t, feed.CircuitOpen, f.Health().State)
+
+	srv.mu.Lock()
+	srv.status = 0
+	srv.mu.Unlock()
+
+	// Expect the circuit to close and the initial feed to be published
+	var closed, published bool
+	for !closed || !published {
+		select {
+		case e := <-recvCh:
+			switch e := e.(type) {
+			case feed.CircuitEvent:
+				assert.Equal(t, feed.CircuitClosed, e.Health.State)
+				closed = true
+			case feed.NewItemsEvent:
+				published = true
+			}
+		case <-time.After(50 * time.Millisecond):
+			t.Fatal("circuit didn't close after recovery")
+		}
+	}
+}
+
+func TestFeedBackoffDefaults(t *testing.T) {
+	srv, err := NewFakeServer(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.status = http.StatusInternalServerError
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := feed.NewFeed(ctx, srv.server.URL)
+	f.SetRefreshInterval(time.Millisecond)
+	// Without Max the failing feed must not be fetched without delay
+	f.SetBackoff(feed.BackoffPolicy{Base: time.Minute})
+	f.Subscribe()
+
+	<-time.After(20 * time.Millisecond)
+	assert.Equal(t, int64(1), srv.requests.Load())
+	assert.Equal(t, feed.CircuitClosed, f.Health().State)
+}
+
+func TestBackoffPolicyDelay(t *testing.T) {
+	p := feed.BackoffPolicy{Base: time.Second, Max: 10 * time.Second}
+
+	assert.Equal(t, time.Duration(0), p.Delay(0))
+	assert.Equal(t, time.Second, p.Delay(1))
+	assert.Equal(t, 2*time.Second, p.Delay(2))
+	assert.Equal(t, 8*time.Second, p.Delay(4))
+	assert.Equal(t, 10*time.Second, p.Delay(5))
+	assert.Equal(t, 10*time.Second, p.Delay(100))
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := p.Delay(2)
+		assert.GreaterOrEqual(t, d, time.Second)
+		assert.LessOrEqual(t, d, 3*time.Second)
+	}
+}
+
+func TestFeedVanishedSubscriber(t *testing.T) {
+	srv, err := NewFakeServer(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.server.Close()
+	goroutinesBefore := runtime.NumGoroutine()
+
+	f := feed.NewFeed(context.Background(), srv.server.URL)
+	f.SetRefreshInterval(time.Millisecond)
+	// Never reads a single event
+	f.SubscribeWithConfig(feed.SubscriptionConfig{Policy: feed.BlockWithTimeout, Timeout: 5 * time.Millisecond})
+	recvCh := f.Subscribe().Events()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-recvCh:
+		case <-time.After(50 * time.Millisecond):
+			t.Fatal("vanished subscriber blocked delivery")
+		}
+		srv.rotateFeed()
+	}
+
+	f.Close()
+	// Drain remaining buffered events, the channel has to be closed
+	for range recvCh {
+	}
+
+	http.DefaultTransport.(*http.Transport).CloseIdleConnections()
+	assert.Eventually(t, func() bool {
+		return runtime.NumGoroutine() <= goroutinesBefore
+	}, time.Second, time.Millisecond, "goroutines leaked after close")
+}
+
+func TestFeedDeliveryPolicies(t *testing.T) {
+	srv, err := NewFakeServer(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := feed.NewFeed(ctx, srv.server.URL)
+	f.SetRefreshInterval(time.Millisecond)
+	dropOldest := f.SubscribeWithConfig(feed.SubscriptionConfig{Policy: feed.DropOldest, Buffer: 1})
+	dropNewest := f.SubscribeWithConfig(feed.SubscriptionConfig{Policy: feed.DropNewest, Buffer: 1})
+	// Synchronizes the test with the published events
+	recvCh := f.Subscribe().Events()
+
+	var events []feed.NewItemsEvent
+	for i := 0; i < 3; i++ {
+		select {
+		case e := <-recvCh:
+			events = append(events, e.(feed.NewItemsEvent))
+		case <-time.After(50 * time.Millisecond):
+			t.Fatal("didn't receive feed update")
+		}
+		if i < 2 {
+			srv.rotateFeed()
+		}
+	}
+	// Let the concurrent deliveries of the last event finish
+	<-time.After(5 * time.Millisecond)
+
+	firstItemID := func(e feed.Event) string {
+		return feed.ItemID(e.(feed.NewItemsEvent).Items[0])
+	}
+	assert.Equal(t, firstItemID(events[2]), firstItemID(<-dropOldest.Events()))
+	assert.Equal(t, firstItemID(events[0]), firstItemID(<-dropNewest.Events()))
+}
+
+func TestFeedUnsubscribe(t *testing.T) {
+	srv, err := NewFakeServer(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := feed.NewFeed(ctx, srv.server.URL)
+	f.SetRefreshInterval(time.Millisecond)
+	sub := f.Subscribe()
+	recvCh := f.Subscribe().Events()
+
+	<-sub.Events()
+	<-recvCh
+	sub.Unsubscribe()
+	_, open := <-sub.Events()
+	assert.False(t, open, "subscription wasn't closed")
+
+	// Remaining subscriptions still receive events
+	srv.rotateFeed()
+	select {
+	case <-recvCh:
+	case <-time.After(50 * time.Millisecond):
+		t.Fatal("didn't receive feed update after unsubscribe of other subscriber")
+	}
+}
+
 func BenchmarkFeed(b *testing.B) {
 	srv, err := NewFakeServer(true)
 	if err != nil {
@@ -66,7 +453,7 @@ func BenchmarkFeed(b *testing.B) {
 	ctx, cancel := context.WithCancel(context.Background())
 	feed := feed.NewFeed(ctx, srv.server.URL)
 	feed.SetRefreshInterval(0)
-	recvCh := feed.Subscribe()
+	recvCh := feed.Subscribe().Events()
 	for i := 0; i < b.N; i++ {
 		<-recvCh
 	}
@@ -116,6 +503,12 @@ type fakeServer struct {
 	mu       sync.RWMutex
 	server   *httptest.Server
 	feedData tplData
+
+	etags       bool        // answer conditional requests using ETags
+	status      int         // respond with this status code instead of the feed if set
+	header      http.Header // additional response headers
+	requests    atomic.Int64
+	notModified atomic.Int64
 }
 
 func NewFakeServer(autorotate bool) (*fakeServer, error) {
@@ -124,16 +517,33 @@ func NewFakeServer(autorotate bool) (*fakeServer, error) {
 		return nil, fmt.Errorf("create initial fake feed: %w", err)
 	}
 
-	f := &fakeServer{feedData: feedData}
+	f := &fakeServer{feedData: feedData, header: make(http.Header)}
 	f.server = httptest.NewServer(func() http.HandlerFunc {
 		reqs := 0
 		return func(w http.ResponseWriter, r *http.Request) {
 			if autorotate && reqs%autoRotateAfter == 0 {
 				f.rotateFeed()
 			}
-			w.WriteHeader(http.StatusOK)
+			f.requests.Inc()
 			f.mu.RLock()
 			defer f.mu.RUnlock()
+			for k, v := range f.header {
+				w.Header()[k] = v
+			}
+			if f.status != 0 {
+				w.WriteHeader(f.status)
+				return
+			}
+			if f.etags {
+				etag := fmt.Sprintf(`"%d"`, f.feedData.Published.UnixNano())
+				w.Header().Set("ETag", etag)
+				if r.Header.Get("If-None-Match") == etag {
+					f.notModified.Inc()
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+			}
+			w.WriteHeader(http.StatusOK)
 			WriteFakeFeed(w, f.feedData)
 			reqs++
 		}
@@ -154,6 +564,38 @@ func (f *fakeServer) rotateFeed() error {
 	return nil
 }
 
+// shuffleFeed reverses the item order and bumps the publish date without
+// adding any new items.
+func (f *fakeServer) shuffleFeed() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	items := make([]feedItem, len(f.feedData.Items))
+	for i, item := range f.feedData.Items {
+		items[len(items)-1-i] = item
+	}
+	f.feedData = tplData{
+		Published: f.feedData.Published.Add(time.Minute),
+		Items:     items,
+	}
+}
+
+// addItems prepends n newly generated items to the feed.
+func (f *fakeServer) addItems(n uint) error {
+	added, err := generateFakeFeed(n)
+	if err != nil {
+		return fmt.Errorf("generate additional feed items: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.feedData = tplData{
+		Published: time.Now(),
+		Items:     append(added.Items, f.feedData.Items...),
+	}
+	return nil
+}
+
 const tplStr = `
 <rss xmlns:atom="http://www.w3.org/2005/Atom" version="2.0">
 <channel>
@@ -0,0 +1,189 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+var (
+	ErrFeedExists    = errors.New("feed already exists")
+	ErrFeedNotFound  = errors.New("feed not found")
+	ErrManagerClosed = errors.New("manager closed")
+)
+
+// Config describes a feed owned by a Manager.
+type Config struct {
+	// Name identifies the feed within the manager and tags its events.
+	Name string
+	URL  string
+	// RefreshInterval defaults to the feed default if zero.
+	RefreshInterval time.Duration
+	// Translator is optional.
+	Translator gofeed.Translator
+	// Backoff defaults to DefaultBackoffPolicy if nil.
+	Backoff *BackoffPolicy
+}
+
+// SourcedEvent is an event tagged with the name of the feed it originates from.
+type SourcedEvent struct {
+	Source string
+	Event  Event
+}
+
+type managedFeed struct {
+	feed   *Feed
+	cancel context.CancelFunc
+}
+
+// Manager polls a set of named feeds concurrently and merges their events
+// into a single stream.
+type Manager struct {
+	mu     sync.Mutex
+	closed bool
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	store  Store
+	feeds  map[string]managedFeed
+	events chan SourcedEvent
+	wg     sync.WaitGroup // running forwarders
+}
+
+// NewManager returns a manager whose feeds use store to keep track of
+// seen items. A nil store gives each feed its own in-memory store.
+func NewManager(ctx context.Context, store Store) *Manager {
+	ctx, cancel := context.WithCancel(ctx)
+	m := &Manager{
+		ctx:    ctx,
+		cancel: cancel,
+		store:  store,
+		feeds:  make(map[string]managedFeed),
+		events: make(chan SourcedEvent),
+	}
+
+	go func() {
+		<-ctx.Done()
+		m.Close()
+	}()
+
+	return m
+}
+
+// Events returns the merged events of all feeds. It is closed once the
+// manager is closed.
+func (m *Manager) Events() <-chan SourcedEvent {
+	return m.events
+}
+
+// Add starts polling a new feed.
+func (m *Manager) Add(cfg Config) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return ErrManagerClosed
+	}
+	if _, ok := m.feeds[cfg.Name]; ok {
+		return fmt.Errorf("%w: %v", ErrFeedExists, cfg.Name)
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	f := NewFeed(ctx, cfg.URL)
+	if cfg.RefreshInterval > 0 {
+		f.SetRefreshInterval(cfg.RefreshInterval)
+	}
+	if cfg.Translator != nil {
+		f.SetTranslator(cfg.Translator)
+	}
+	if cfg.Backoff != nil {
+		f.SetBackoff(*cfg.Backoff)
+	}
+	if m.store != nil {
+		f.SetStore(m.store)
+	}
+	m.feeds[cfg.Name] = managedFeed{feed: f, cancel: cancel}
+
+	m.wg.Add(1)
+	go m.forward(ctx, cfg.Name, f.Subscribe())
+
+	return nil
+}
+
+// Remove stops polling the feed and closes it.
+func (m *Manager) Remove(name string) error {
+	m.mu.Lock()
+	mf, ok := m.feeds[name]
+	delete(m.feeds, name)
+	m.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrFeedNotFound, name)
+	}
+
+	mf.cancel()
+	mf.feed.Close()
+	return nil
+}
+
+// Feed returns the feed with the given name.
+func (m *Manager) Feed(name string) (*Feed, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mf, ok := m.feeds[name]
+	return mf.feed, ok
+}
+
+// Names returns the sorted names of all feeds.
+func (m *Manager) Names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.feeds))
+	for name := range m.feeds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close closes all feeds and the event stream.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	feeds := m.feeds
+	m.feeds = make(map[string]managedFeed)
+	m.mu.Unlock()
+
+	m.cancel()
+	for _, mf := range feeds {
+		mf.feed.Close()
+	}
+
+	m.wg.Wait()
+	close(m.events)
+}
+
+// forward passes the events of a subscription on to the merged stream
+// until the feed is removed.
+func (m *Manager) forward(ctx context.Context, name string, sub *Subscription) {
+	defer m.wg.Done()
+
+	for e := range sub.Events() {
+		select {
+		case m.events <- SourcedEvent{Source: name, Event: e}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
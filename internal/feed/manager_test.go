@@ -0,0 +1,90 @@
+package feed_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jgraeger/bverfgbot/internal/feed"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager(t *testing.T) {
+	decisions, err := NewFakeServer(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	press, err := NewFakeServer(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := feed.NewManager(context.Background(), feed.NewMemoryStore())
+	defer m.Close()
+
+	assert.NoError(t, m.Add(feed.Config{Name: "decisions", URL: decisions.server.URL, RefreshInterval: time.Millisecond}))
+	err = m.Add(feed.Config{Name: "decisions", URL: press.server.URL})
+	assert.True(t, errors.Is(err, feed.ErrFeedExists))
+
+	// Feeds can be added while the manager is running
+	e := receiveSourced(t, m)
+	assert.Equal(t, "decisions", e.Source)
+	assert.NoError(t, m.Add(feed.Config{Name: "press", URL: press.server.URL, RefreshInterval: time.Millisecond}))
+	e = receiveSourced(t, m)
+	assert.Equal(t, "press", e.Source)
+	assert.Len(t, e.Event.(feed.NewItemsEvent).Items, numTestFeedItems)
+	assert.Equal(t, []string{"decisions", "press"}, m.Names())
+
+	// Removed feeds don't publish anymore
+	assert.NoError(t, m.Remove("decisions"))
+	assert.True(t, errors.Is(m.Remove("decisions"), feed.ErrFeedNotFound))
+	_, ok := m.Feed("decisions")
+	assert.False(t, ok)
+
+	decisions.rotateFeed()
+	press.rotateFeed()
+	e = receiveSourced(t, m)
+	assert.Equal(t, "press", e.Source)
+	select {
+	case e := <-m.Events():
+		t.Fatalf("received event of removed feed %v", e.Source)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestManagerClose(t *testing.T) {
+	srv, err := NewFakeServer(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := feed.NewManager(ctx, nil)
+	assert.NoError(t, m.Add(feed.Config{Name: "decisions", URL: srv.server.URL, RefreshInterval: time.Millisecond}))
+	cancel()
+
+	select {
+	case _, open := <-m.Events():
+		if open {
+			// An event might have been in flight before the cancellation
+			_, open = <-m.Events()
+		}
+		assert.False(t, open)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("events weren't closed after cancellation")
+	}
+	assert.True(t, errors.Is(m.Add(feed.Config{Name: "press", URL: srv.server.URL}), feed.ErrManagerClosed))
+}
+
+func receiveSourced(t *testing.T, m *feed.Manager) feed.SourcedEvent {
+	t.Helper()
+
+	select {
+	case e := <-m.Events():
+		return e
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("didn't receive event")
+	}
+	return feed.SourcedEvent{}
+}
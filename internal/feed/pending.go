@@ -0,0 +1,74 @@
+package feed
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// PendingItem is a received feed item whose handling has been deferred.
+type PendingItem struct {
+	Feed string
+	// ID identifies the item within the feed to the application.
+	ID       string
+	Item     *gofeed.Item
+	Received time.Time
+}
+
+// PendingStore persists received items until the application is done
+// handling them. Acknowledging items once they are stored keeps items held
+// back by the application from being lost on restart.
+type PendingStore interface {
+	// AddPending stores an item, replacing a pending item with the same ID.
+	AddPending(ctx context.Context, item PendingItem) error
+	// RemovePending removes the item with the given ID of the feed.
+	RemovePending(ctx context.Context, feed string, id string) error
+	// Pending returns all pending items, oldest first.
+	Pending(ctx context.Context) ([]PendingItem, error)
+}
+
+// MemoryPendingStore is a non-persistent PendingStore, mainly useful for tests.
+type MemoryPendingStore struct {
+	mu    sync.Mutex
+	items map[[2]string]PendingItem
+}
+
+func NewMemoryPendingStore() *MemoryPendingStore {
+	return &MemoryPendingStore{
+		items: make(map[[2]string]PendingItem),
+	}
+}
+
+func (s *MemoryPendingStore) AddPending(_ context.Context, item PendingItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items[[2]string{item.Feed, item.ID}] = item
+	return nil
+}
+
+func (s *MemoryPendingStore) RemovePending(_ context.Context, feed string, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.items, [2]string{feed, id})
+	return nil
+}
+
+func (s *MemoryPendingStore) Pending(_ context.Context) ([]PendingItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := make([]PendingItem, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Received.Before(items[j].Received)
+	})
+
+	return items, nil
+}
@@ -0,0 +1,32 @@
+package feed_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jgraeger/bverfgbot/internal/feed"
+	"github.com/mmcdole/gofeed"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryPendingStore(t *testing.T) {
+	ctx := context.Background()
+	store := feed.NewMemoryPendingStore()
+	now := time.Now()
+
+	decision := feed.PendingItem{Feed: "decisions", ID: "a", Item: &gofeed.Item{Title: "A"}, Received: now}
+	press := feed.PendingItem{Feed: "press", ID: "a", Item: &gofeed.Item{Title: "B"}, Received: now.Add(-time.Minute)}
+	require.NoError(t, store.AddPending(ctx, decision))
+	require.NoError(t, store.AddPending(ctx, press))
+
+	pending, err := store.Pending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []feed.PendingItem{press, decision}, pending)
+
+	require.NoError(t, store.RemovePending(ctx, "press", "a"))
+	pending, err = store.Pending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []feed.PendingItem{decision}, pending)
+}
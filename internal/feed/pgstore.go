@@ -0,0 +1,183 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const createSeenItemsQuery = `
+	CREATE TABLE IF NOT EXISTS feed_seen_items (
+		feed    TEXT NOT NULL,
+		item_id TEXT NOT NULL,
+		seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (feed, item_id)
+	);`
+
+const hasSeenQuery = `
+	SELECT EXISTS (
+		SELECT 1
+		FROM feed_seen_items
+		WHERE feed = $1
+	);`
+
+const seenItemsQuery = `
+	SELECT item_id
+	FROM feed_seen_items
+	WHERE feed = $1 AND item_id = ANY($2);`
+
+const markSeenQuery = `
+	INSERT INTO feed_seen_items (feed, item_id)
+	SELECT $1, unnest($2::TEXT[])
+	ON CONFLICT DO NOTHING;`
+
+// PostgresStore is a Store persisting seen items in a postgres table.
+type PostgresStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresStore returns a store using db, creating the table
+// if it doesn't exist yet.
+func NewPostgresStore(ctx context.Context, db *pgxpool.Pool) (*PostgresStore, error) {
+	if _, err := db.Exec(ctx, createSeenItemsQuery); err != nil {
+		return nil, fmt.Errorf("creating seen items table: %w", err)
+	}
+
+	return &PostgresStore{db: db}, nil
+}
+
+func (s *PostgresStore) HasSeen(ctx context.Context, feed string) (bool, error) {
+	var exists bool
+	if err := s.db.QueryRow(ctx, hasSeenQuery, feed).Scan(&exists); err != nil {
+		return false, fmt.Errorf("querying seen items: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (s *PostgresStore) Unseen(ctx context.Context, feed string, ids []string) ([]string, error) {
+	rows, err := s.db.Query(ctx, seenItemsQuery, feed, ids)
+	if err != nil {
+		return nil, fmt.Errorf("querying seen items: %w", err)
+	}
+	defer rows.Close()
+
+	seen := make(map[string]struct{}, len(ids))
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scanning row: %w", err)
+		}
+		seen[id] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading seen items: %w", err)
+	}
+
+	var unseen []string
+	for _, id := range ids {
+		if _, ok := seen[id]; !ok {
+			unseen = append(unseen, id)
+		}
+	}
+
+	return unseen, nil
+}
+
+func (s *PostgresStore) MarkSeen(ctx context.Context, feed string, ids []string) error {
+	if _, err := s.db.Exec(ctx, markSeenQuery, feed, ids); err != nil {
+		return fmt.Errorf("marking items seen: %w", err)
+	}
+
+	return nil
+}
+
+const createPendingItemsQuery = `
+	CREATE TABLE IF NOT EXISTS feed_pending_items (
+		feed        TEXT NOT NULL,
+		id          TEXT NOT NULL,
+		item        JSONB NOT NULL,
+		received_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (feed, id)
+	);`
+
+const addPendingQuery = `
+	INSERT INTO feed_pending_items (feed, id, item, received_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (feed, id) DO UPDATE
+	SET item = EXCLUDED.item, received_at = EXCLUDED.received_at;`
+
+const removePendingQuery = `
+	DELETE FROM feed_pending_items
+	WHERE feed = $1 AND id = $2;`
+
+const pendingItemsQuery = `
+	SELECT feed, id, item, received_at
+	FROM feed_pending_items
+	ORDER BY received_at;`
+
+// PostgresPendingStore is a PendingStore keeping the items in a postgres
+// table.
+type PostgresPendingStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresPendingStore returns a store using db, creating the table
+// if it doesn't exist yet.
+func NewPostgresPendingStore(ctx context.Context, db *pgxpool.Pool) (*PostgresPendingStore, error) {
+	if _, err := db.Exec(ctx, createPendingItemsQuery); err != nil {
+		return nil, fmt.Errorf("creating pending items table: %w", err)
+	}
+
+	return &PostgresPendingStore{db: db}, nil
+}
+
+func (s *PostgresPendingStore) AddPending(ctx context.Context, item PendingItem) error {
+	data, err := json.Marshal(item.Item)
+	if err != nil {
+		return fmt.Errorf("encoding pending item: %w", err)
+	}
+	if _, err := s.db.Exec(ctx, addPendingQuery, item.Feed, item.ID, data, item.Received); err != nil {
+		return fmt.Errorf("adding pending item: %w", err)
+	}
+
+	return nil
+}
+
+func (s *PostgresPendingStore) RemovePending(ctx context.Context, feed string, id string) error {
+	if _, err := s.db.Exec(ctx, removePendingQuery, feed, id); err != nil {
+		return fmt.Errorf("removing pending item: %w", err)
+	}
+
+	return nil
+}
+
+func (s *PostgresPendingStore) Pending(ctx context.Context) ([]PendingItem, error) {
+	rows, err := s.db.Query(ctx, pendingItemsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("querying pending items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []PendingItem
+	for rows.Next() {
+		var (
+			item PendingItem
+			data []byte
+		)
+		if err := rows.Scan(&item.Feed, &item.ID, &data, &item.Received); err != nil {
+			return nil, fmt.Errorf("scanning row: %w", err)
+		}
+		if err := json.Unmarshal(data, &item.Item); err != nil {
+			return nil, fmt.Errorf("decoding pending item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading pending items: %w", err)
+	}
+
+	return items, nil
+}
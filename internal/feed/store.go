@@ -0,0 +1,66 @@
+package feed
+
+import (
+	"context"
+	"sync"
+)
+
+// Store persists the IDs of feed items that have already been published,
+// so a restarted Feed only publishes items that haven't been delivered yet.
+type Store interface {
+	// HasSeen reports whether any item has been marked seen for the feed.
+	HasSeen(ctx context.Context, feed string) (bool, error)
+	// Unseen returns the subset of ids that hasn't been marked seen for the feed.
+	Unseen(ctx context.Context, feed string, ids []string) ([]string, error)
+	// MarkSeen marks the given item ids of the feed as seen.
+	MarkSeen(ctx context.Context, feed string, ids []string) error
+}
+
+// MemoryStore is a non-persistent Store, mainly useful for tests.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	feeds map[string]map[string]struct{}
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		feeds: make(map[string]map[string]struct{}),
+	}
+}
+
+func (s *MemoryStore) HasSeen(_ context.Context, feed string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.feeds[feed]) > 0, nil
+}
+
+func (s *MemoryStore) Unseen(_ context.Context, feed string, ids []string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var unseen []string
+	for _, id := range ids {
+		if _, ok := s.feeds[feed][id]; !ok {
+			unseen = append(unseen, id)
+		}
+	}
+
+	return unseen, nil
+}
+
+func (s *MemoryStore) MarkSeen(_ context.Context, feed string, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen, ok := s.feeds[feed]
+	if !ok {
+		seen = make(map[string]struct{}, len(ids))
+		s.feeds[feed] = seen
+	}
+	for _, id := range ids {
+		seen[id] = struct{}{}
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package feed
+
+import (
+	"sync"
+	"time"
+)
+
+// DeliveryPolicy decides what happens to events for subscribers that
+// don't keep up with reading them.
+type DeliveryPolicy int
+
+const (
+	// BlockWithTimeout waits for the subscriber to receive the event until
+	// the timeout elapses and drops the event afterwards.
+	BlockWithTimeout DeliveryPolicy = iota
+	// DropOldest discards the oldest buffered event to make room for the
+	// new one.
+	DropOldest
+	// DropNewest discards the new event if the buffer is full.
+	DropNewest
+)
+
+// SubscriptionConfig configures the delivery of events to a Subscription.
+type SubscriptionConfig struct {
+	Policy DeliveryPolicy
+	// Timeout is used by BlockWithTimeout. Zero blocks until the
+	// subscription or feed is closed.
+	Timeout time.Duration
+	// Buffer is the capacity of the event channel. The drop policies
+	// require at least one.
+	Buffer int
+}
+
+// DefaultSubscriptionConfig is used by Feed.Subscribe.
+var DefaultSubscriptionConfig = SubscriptionConfig{
+	Policy:  BlockWithTimeout,
+	Timeout: time.Minute,
+	Buffer:  16,
+}
+
+// Subscription receives the events of a Feed until it is unsubscribed
+// or the feed is closed.
+type Subscription struct {
+	feed *Feed
+	cfg  SubscriptionConfig
+
+	mu        sync.Mutex // held while sending to ch
+	ch        chan Event
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newSubscription(f *Feed, cfg SubscriptionConfig) *Subscription {
+	if cfg.Policy != BlockWithTimeout && cfg.Buffer < 1 {
+		cfg.Buffer = 1
+	}
+
+	return &Subscription{
+		feed: f,
+		cfg:  cfg,
+		ch:   make(chan Event, cfg.Buffer),
+		done: make(chan struct{}),
+	}
+}
+
+// Events returns the channel the events are delivered on. It is closed
+// when the subscription ends.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Unsubscribe ends the subscription and closes its event channel.
+func (s *Subscription) Unsubscribe() {
+	s.feed.unsubscribe(s)
+	s.close()
+}
+
+func (s *Subscription) close() {
+	s.closeOnce.Do(func() {
+		// Unblock pending deliveries before closing the channel
+		close(s.done)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		close(s.ch)
+	})
+}
+
+// deliver sends e according to the delivery policy and reports whether
+// the event was delivered.
+func (s *Subscription) deliver(e Event) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return false
+	default:
+	}
+
+	switch s.cfg.Policy {
+	case DropNewest:
+		select {
+		case s.ch <- e:
+			return true
+		default:
+			return false
+		}
+	case DropOldest:
+		for {
+			select {
+			case s.ch <- e:
+				return true
+			default:
+			}
+			select {
+			case <-s.ch:
+			default:
+			}
+		}
+	default:
+		var timeout <-chan time.Time
+		if s.cfg.Timeout > 0 {
+			timer := time.NewTimer(s.cfg.Timeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		select {
+		case s.ch <- e:
+			return true
+		case <-timeout:
+			return false
+		case <-s.done:
+			return false
+		}
+	}
+}
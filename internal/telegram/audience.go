@@ -0,0 +1,44 @@
+package telegram
+
+import (
+	"github.com/jgraeger/bverfgbot/internal/bverfg"
+)
+
+// audience describes a decision, hearing or announcement to select the
+// chats following it with the decision queries.
+type audience struct {
+	Dissent bool
+	Refs    []bverfg.CaseReference
+	// Senate and Body are zero if unknown.
+	Senate uint8
+	Body   bverfg.DecisionBody
+}
+
+func decisionAudience(d bverfg.Decision) audience {
+	return audience{
+		Dissent: d.HasDissent(),
+		Refs:    d.Refs,
+		Senate:  d.Senate,
+		Body:    d.Body,
+	}
+}
+
+// senateAudience is the audience of hearings and announced decisions,
+// which concern the Senates only.
+func senateAudience(refs []bverfg.CaseReference) audience {
+	a := audience{Refs: refs, Body: bverfg.Senat}
+	if len(refs) > 0 {
+		a.Senate = refs[0].Senate
+	}
+	return a
+}
+
+// args returns the arguments of the decision queries.
+func (a audience) args() []interface{} {
+	types := []string{}
+	for _, ref := range a.Refs {
+		types = append(types, string(ref.Type))
+	}
+
+	return []interface{}{a.Dissent, refStrings(a.Refs), int16(a.Senate), string(a.Body), types}
+}
@@ -7,7 +7,7 @@ import (
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jgraeger/bverfgbot/internal/bverfg"
 	"github.com/mmcdole/gofeed"
 )
@@ -16,19 +16,33 @@ const (
 	botTimeout = 30
 	// The time notify the fellow users for today's senate decisions
 	dailyOutlookHour = 7
+	// How often the scheduled oral hearings are checked for new ones
+	hearingsInterval = time.Hour
+	// Requests failing transiently or being rate limited are retried
+	maxRequestAttempts = 3
+	requestRetryDelay  = time.Second
 )
 
 type Bot struct {
 	ctx context.Context
 
-	api *tgbotapi.BotAPI
-	db  *pgx.Conn
+	api     *tgbotapi.BotAPI
+	db      *pgxpool.Pool
+	limiter *rateLimiter
+
+	updates tgbotapi.UpdatesChannel
+	// webhookUpdates receives the updates posted to the webhook handler
+	webhookUpdates chan tgbotapi.Update
+	secretToken    string
 }
 
-func NewBot(ctx context.Context, token string, postgresDSN string) (*Bot, error) {
-	dbConn, err := pgx.Connect(ctx, postgresDSN)
-	if err != nil {
-		return nil, err
+// NewBot creates a bot receiving its updates on the given webhook or, if
+// webhook is nil, by long polling.
+func NewBot(ctx context.Context, token string, db *pgxpool.Pool, webhook *WebhookConfig) (*Bot, error) {
+	if webhook != nil {
+		if err := webhook.validate(); err != nil {
+			return nil, err
+		}
 	}
 
 	botApi, err := tgbotapi.NewBotAPI(token)
@@ -39,16 +53,50 @@ func NewBot(ctx context.Context, token string, postgresDSN string) (*Bot, error)
 	log.Println("telegram bot authorized on account:", botApi.Self.UserName)
 
 	bot := &Bot{
-		ctx: ctx,
-		api: botApi,
-		db:  dbConn,
+		ctx:     ctx,
+		api:     botApi,
+		db:      db,
+		limiter: newRateLimiter(),
+	}
+
+	if err := bot.migrate(); err != nil {
+		return nil, err
+	}
+
+	if webhook != nil {
+		if err := bot.setWebhook(*webhook); err != nil {
+			return nil, err
+		}
+		bot.webhookUpdates = make(chan tgbotapi.Update, webhookBuffer)
+		bot.secretToken = webhook.SecretToken
+		bot.updates = bot.webhookUpdates
+	} else {
+		// Telegram refuses to be polled as long as a webhook is set
+		if _, err := botApi.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+			return nil, fmt.Errorf("deleting webhook: %w", err)
+		}
+		updateConfig := tgbotapi.NewUpdate(0)
+		updateConfig.Timeout = botTimeout
+		bot.updates = botApi.GetUpdatesChan(updateConfig)
 	}
 
 	go bot.mainLoop()
+	go bot.scheduleLoop()
+	go bot.outboxLoop()
 
 	return bot, nil
 }
 
+func (b *Bot) migrate() error {
+	for _, query := range schemaQueries {
+		if _, err := b.db.Exec(b.ctx, query); err != nil {
+			return fmt.Errorf("migrating schema: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func untilHourOfDay(hour int) time.Duration {
 	if hour < 0 || hour > 23 {
 		log.Fatalf("timeUntilDayHour(%v) is invalid", hour)
@@ -64,33 +112,55 @@ func untilHourOfDay(hour int) time.Duration {
 	return d
 }
 
+// mainLoop handles the updates received from Telegram.
 func (b *Bot) mainLoop() {
-	updateConfig := tgbotapi.NewUpdate(0)
-	updateConfig.Timeout = botTimeout
-
-	updateChan := b.api.GetUpdatesChan(updateConfig)
+	for {
+		select {
+		case u := <-b.updates:
+			if u.Message != nil {
+				b.handleMessage(*u.Message)
+			} else if u.CallbackQuery != nil {
+				b.handleCallbackQuery(*u.CallbackQuery)
+			} else if u.MyChatMember != nil {
+				b.handleMyChatMember(*u.MyChatMember)
+			} else if u.ChatMember != nil {
+				b.handleChatMember(*u.ChatMember)
+			}
+		case <-b.ctx.Done():
+			log.Printf("shutdown telegram loop")
+			return
+		}
+	}
+}
 
+// scheduleLoop runs the scrapes of the court's site, apart from mainLoop so
+// updates are handled while the site is slow.
+func (b *Bot) scheduleLoop() {
 	// Daily upcoming decisions
 	d := untilHourOfDay(dailyOutlookHour)
 	timer := time.NewTimer(d)
+	defer timer.Stop()
 	log.Println("started timer running for:", d)
 
+	// Newly scheduled hearings and the cases to come this year
+	b.handleHearings()
+	b.importDocket()
+	hearingsTicker := time.NewTicker(hearingsInterval)
+	defer hearingsTicker.Stop()
+
 	for {
 		select {
-		case u := <-updateChan:
-			if u.Message != nil {
-				b.handleMessage(*u.Message)
-			} else if u.ChatMember != nil {
-				b.handleChatMember(*u.ChatMember)
-			}
+		case <-hearingsTicker.C:
+			b.handleHearings()
 		case <-timer.C:
 			b.handleDailyOutlook()
+			b.handleTodaysHearings()
+			b.importDocket()
 			d = untilHourOfDay(dailyOutlookHour)
 			timer.Reset(d)
 			log.Println("timer reseted for:", d)
 		case <-b.ctx.Done():
-			log.Printf("shutdown telegram loop")
-			b.shutdown()
+			log.Printf("shutdown schedule loop")
 			return
 		}
 	}
@@ -101,10 +171,16 @@ func (b *Bot) handleDailyOutlook() {
 	defer func() { log.Println("finished daily outlook handler") }()
 	todayDate := time.Now().Truncate(24 * time.Hour)
 
-	for _, upcoming := range bverfg.GetUpcomingSenateDecisions() {
+	upcomingDecisions, err := bverfg.GetUpcomingSenateDecisions(b.ctx)
+	if err != nil {
+		// Announcements without a valid date are left out, the others are still due
+		log.Println("error getting upcoming decisions:", err)
+	}
+
+	for _, upcoming := range upcomingDecisions {
 		pubDate := upcoming.PublishDate.Truncate(24 * time.Hour)
 		if pubDate.Sub(todayDate) < 24*time.Hour {
-			log.Printf("decision %s will come in the next 24hours. notify.", upcoming.Ref)
+			log.Printf("decision %s will come in the next 24hours. notify.", upcoming.RefString())
 
 			msg, err := buildUpcomingDecisionMessage(upcoming)
 			if err != nil {
@@ -112,7 +188,10 @@ func (b *Bot) handleDailyOutlook() {
 				continue
 			}
 
-			b.SendToAll(msg)
+			subject := "upcoming:" + upcoming.RefString() + ":" + upcoming.PublishDate.Format("2006-01-02")
+			if err := b.enqueue(subject, getDecisionSubscribersQuery, msg, senateAudience(upcoming.Refs).args()...); err != nil {
+				log.Printf("error sending upcoming decision message: %v", err)
+			}
 		}
 	}
 }
@@ -123,28 +202,69 @@ func (b *Bot) handleMessage(msg tgbotapi.Message) {
 		fmt.Println("error inserting chat into db", err)
 		return
 	}
+	b.reactivateChat(msg.Chat.ID)
 
-	var responseText string
+	var responseText, parseMode string
 
-	switch msg.Text {
-	case "/start":
+	switch msg.Command() {
+	case "start":
+		if err := b.subscribe(msg.Chat.ID); err != nil {
+			log.Println("error subscribing chat:", err)
+			responseText = errorMessage
+			break
+		}
 		responseText, err = getWelcomeMessage(MessageConfig{FirstName: msg.From.FirstName})
 		if err != nil {
 			log.Println("template error:", err)
 			return
 		}
+	case "stop":
+		responseText = b.handleStopCommand(msg.Chat.ID)
+	case "status":
+		responseText = b.handleStatusCommand(msg.Chat.ID)
+	case "notify":
+		responseText = b.handleNotifyCommand(msg.Chat.ID, msg.CommandArguments())
+	case "vorausschau":
+		responseText = b.handleDocketCommand(msg.CommandArguments())
+		parseMode = tgbotapi.ModeHTML
+	case "follow":
+		responseText = b.handleFollowCommand(msg.Chat.ID, msg.CommandArguments())
+	case "unfollow":
+		responseText = b.handleUnfollowCommand(msg.Chat.ID, msg.CommandArguments())
+	case "following":
+		responseText = b.handleFollowingCommand(msg.Chat.ID)
+	case "settings":
+		b.handleSettingsCommand(msg)
+		return
 	default:
 		return
 	}
 
 	response := tgbotapi.NewMessage(msg.Chat.ID, responseText)
 	response.ReplyToMessageID = msg.MessageID
+	response.ParseMode = parseMode
 
-	if _, err := b.api.Send(response); err != nil {
+	if err := b.send(msg.Chat.ID, response); err != nil {
 		log.Println("error sending message:", err)
 	}
 }
 
+// handleNotifyCommand sets the topics the chat is notified about.
+func (b *Bot) handleNotifyCommand(chatID int64, args string) string {
+	topics, ok := parseTopics(args)
+	if !ok {
+		return notifyUsageMessage
+	}
+
+	if _, err := b.db.Exec(b.ctx, setTopicsQuery, chatID,
+		topics.Decisions, topics.PressReleases, topics.Dissents, topics.Docket); err != nil {
+		log.Println("error updating chat topics:", err)
+		return errorMessage
+	}
+
+	return getTopicsMessage(topics)
+}
+
 func (b *Bot) handleChatMember(update tgbotapi.ChatMemberUpdated) {
 	_, err := b.db.Exec(b.ctx, storeChatQuery, update.Chat.ID, update.From.FirstName, update.From.LastName)
 	if err != nil {
@@ -159,56 +279,118 @@ func (b *Bot) handleChatMember(update tgbotapi.ChatMemberUpdated) {
 	}
 
 	response := tgbotapi.NewMessage(update.Chat.ID, responseText)
-	if _, err := b.api.Send(response); err != nil {
+	if err := b.send(update.Chat.ID, response); err != nil {
 		log.Println("error sending message:", err)
 	}
 }
 
 func (b *Bot) DoNothing() {}
 
-func (b *Bot) NotifyDecision(item *gofeed.Item) error {
-	msgString, err := buildDecisionMessage(item)
-	if err != nil {
-		return err
-	}
+func (b *Bot) NotifyDecision(d bverfg.Decision) error {
+	return b.NotifyCorrelated(bverfg.Correlated{Decision: &d})
+}
 
-	return b.SendToAll(msgString)
+func (b *Bot) NotifyPressRelease(item *gofeed.Item) error {
+	return b.NotifyCorrelated(bverfg.Correlated{PressRelease: item})
 }
 
-func (b *Bot) SendToAll(msg string) error {
-	rows, err := b.db.Query(b.ctx, getAllQuery)
-	if err != nil {
-		return fmt.Errorf("error sending to all users: %w", err)
+// NotifyCorrelated announces a decision and/or press release, linking to
+// the related counterpart if there is one. Merged ones are sent as a single
+// message to the chats following both topics.
+func (b *Bot) NotifyCorrelated(c bverfg.Correlated) error {
+	if c.Merged() {
+		return b.notifyMerged(c.Decision, c.PressRelease)
 	}
 
-	var chats []int64
-	for rows.Next() {
-		var id int64
-		err = rows.Scan(&id)
+	if c.Decision != nil {
+		msg, err := buildDecisionMessage(*c.Decision, c.RelatedPressRelease, b.onDocket(c.Decision.Refs))
 		if err != nil {
-			return fmt.Errorf("scanning row: %w", err)
+			return err
 		}
-		chats = append(chats, id)
+		return b.enqueue(decisionSubject(*c.Decision), getDecisionSubscribersQuery, msg, decisionAudience(*c.Decision).args()...)
 	}
 
-	sent := 0
-	for _, chatId := range chats {
-		tgMsg := tgbotapi.NewMessage(chatId, msg)
-		tgMsg.ParseMode = tgbotapi.ModeHTML
-
-		if _, err := b.api.Send(tgMsg); err != nil {
-			log.Println("error sending msg:", err)
-		}
-
-		sent++
-		if sent%30 == 0 {
-			<-time.After(1 * time.Second)
+	if c.PressRelease != nil {
+		msg, err := buildPressReleaseMessage(c.PressRelease, c.RelatedDecision)
+		if err != nil {
+			return err
 		}
+		refs := bverfg.FindCaseRefs(c.PressRelease.Title + "\n" + c.PressRelease.Description)
+		return b.enqueue(pressReleaseSubject(c.PressRelease), getPressSubscribersQuery, msg, refStrings(refs))
 	}
 
 	return nil
 }
 
-func (b *Bot) shutdown() {
-	b.db.Close(context.Background())
+// notifyMerged sends chats following only one topic the respective message
+// linking to its counterpart, the others get a single combined message.
+func (b *Bot) notifyMerged(decision *bverfg.Decision, pressRelease *gofeed.Item) error {
+	docket := b.onDocket(decision.Refs)
+	mergedMsg, err := buildMergedMessage(*decision, pressRelease, docket)
+	if err != nil {
+		return err
+	}
+	decisionMsg, err := buildDecisionMessage(*decision, pressRelease, docket)
+	if err != nil {
+		return err
+	}
+	pressMsg, err := buildPressReleaseMessage(pressRelease, decision)
+	if err != nil {
+		return err
+	}
+
+	args := decisionAudience(*decision).args()
+	if err := b.enqueue(decisionSubject(*decision), getAllTopicsSubscribersQuery, mergedMsg, args...); err != nil {
+		return err
+	}
+	if err := b.enqueue(decisionSubject(*decision), getDecisionOnlySubscribersQuery, decisionMsg, args...); err != nil {
+		return err
+	}
+	return b.enqueue(pressReleaseSubject(pressRelease), getPressOnlySubscribersQuery, pressMsg, args...)
+}
+
+func (b *Bot) SendToAll(msg string) error {
+	return b.enqueue("announcement:"+time.Now().Format(time.RFC3339), getAllQuery, msg)
+}
+
+// decisionSubject identifies the broadcasts of a decision in the outbox.
+func decisionSubject(d bverfg.Decision) string {
+	return "decision:" + d.ID()
+}
+
+func pressReleaseSubject(item *gofeed.Item) string {
+	if item.GUID != "" {
+		return "press:" + item.GUID
+	}
+	return "press:" + item.Link
+}
+
+// send makes the request c to the chat within the rate limits, retrying it
+// after transient errors and as long as Telegram asks. A zero chatID only
+// takes the global rate limit into account.
+func (b *Bot) send(chatID int64, c tgbotapi.Chattable) error {
+	for attempt := 1; ; attempt++ {
+		if err := b.limiter.Wait(b.ctx, chatID); err != nil {
+			return err
+		}
+
+		_, err := b.api.Request(c)
+		if err == nil || attempt == maxRequestAttempts {
+			return err
+		}
+
+		switch class, _ := classifyError(err); class {
+		case errRateLimited:
+			log.Printf("rate limited by telegram, retrying after %v", retryAfter(err))
+			b.limiter.Pause(retryAfter(err))
+		case errTransient:
+			select {
+			case <-time.After(time.Duration(attempt) * requestRetryDelay):
+			case <-b.ctx.Done():
+				return err
+			}
+		default:
+			return err
+		}
+	}
 }
@@ -0,0 +1,63 @@
+package telegram
+
+import (
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleMyChatMember tracks the bot's own membership, as private chats
+// blocking it report as kicked and groups removing it as left or kicked.
+// Groups adding the bot are asked to subscribe with /start, only that
+// subscribes a chat.
+func (b *Bot) handleMyChatMember(update tgbotapi.ChatMemberUpdated) {
+	chatID := update.Chat.ID
+
+	switch update.NewChatMember.Status {
+	case "kicked":
+		reason := reasonKicked
+		if update.Chat.IsPrivate() {
+			reason = reasonBlocked
+		}
+		b.markChatInactive(chatID, reason)
+	case "left":
+		b.markChatInactive(chatID, reasonLeft)
+	case "member", "administrator", "restricted":
+		if _, err := b.db.Exec(b.ctx, storeChatQuery, chatID, update.From.FirstName, update.From.LastName); err != nil {
+			log.Println("error inserting chat into db", err)
+			return
+		}
+		b.reactivateChat(chatID)
+
+		added := update.OldChatMember.HasLeft() || update.OldChatMember.WasKicked()
+		if !added || update.Chat.IsPrivate() {
+			return
+		}
+		if err := b.send(chatID, tgbotapi.NewMessage(chatID, addedToGroupMessage)); err != nil {
+			log.Println("error sending message:", err)
+		}
+	}
+}
+
+// markChatInactive excludes the chat from all broadcasts until it reaches
+// out to the bot again.
+func (b *Bot) markChatInactive(chatID int64, reason string) {
+	if _, err := b.db.Exec(b.ctx, markChatInactiveQuery, chatID, reason); err != nil {
+		log.Printf("error marking chat %d inactive: %v", chatID, err)
+		return
+	}
+
+	log.Printf("marked chat %d inactive: %s", chatID, reason)
+}
+
+func (b *Bot) reactivateChat(chatID int64) {
+	tag, err := b.db.Exec(b.ctx, reactivateChatQuery, chatID)
+	if err != nil {
+		log.Printf("error reactivating chat %d: %v", chatID, err)
+		return
+	}
+
+	if tag.RowsAffected() > 0 {
+		log.Printf("reactivated chat %d", chatID)
+	}
+}
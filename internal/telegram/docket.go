@@ -0,0 +1,83 @@
+package telegram
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jgraeger/bverfgbot/internal/bverfg"
+)
+
+// importDocket stores the annual docket preview of the current year.
+func (b *Bot) importDocket() {
+	year := time.Now().In(bverfg.CourtLocation()).Year()
+
+	preview, err := bverfg.GetDocketPreview(b.ctx, year)
+	var scrapeErr *bverfg.ScrapeError
+	if err != nil && !errors.As(err, &scrapeErr) {
+		log.Printf("error getting docket preview %d: %v", year, err)
+		return
+	}
+	if err != nil {
+		// The entries listed in the scrape error are missing, import the rest
+		log.Printf("error parsing docket preview %d: %v", year, err)
+	}
+
+	imported := 0
+	for _, entry := range preview.Entries {
+		_, err := b.db.Exec(b.ctx, storeDocketEntryQuery,
+			entry.Ref.String(), int(entry.Ref.Senate), string(entry.Ref.Type), preview.Year, entry.Subject, entry.Rapporteur)
+		if err != nil {
+			log.Printf("error storing docket entry %v: %v", entry.Ref, err)
+			continue
+		}
+		imported++
+	}
+	log.Printf("imported %d of %d entries of docket preview %d", imported, len(preview.Entries), year)
+}
+
+// onDocket reports whether any of the cases is on the docket preview.
+func (b *Bot) onDocket(refs []bverfg.CaseReference) bool {
+	var exists bool
+	if err := b.db.QueryRow(b.ctx, isOnDocketQuery, refStrings(refs)).Scan(&exists); err != nil {
+		log.Println("error querying docket entries:", err)
+		return false
+	}
+	return exists
+}
+
+// handleDocketCommand lists the docket preview filtered by senate and
+// register.
+func (b *Bot) handleDocketCommand(args string) string {
+	senate, register, ok := parseDocketFilter(args)
+	if !ok {
+		return docketUsageMessage
+	}
+
+	rows, err := b.db.Query(b.ctx, getDocketEntriesQuery, senate, register)
+	if err != nil {
+		log.Println("error querying docket entries:", err)
+		return errorMessage
+	}
+	defer rows.Close()
+
+	var entries []docketEntry
+	for rows.Next() {
+		var entry docketEntry
+		if err := rows.Scan(&entry.Ref, &entry.Subject); err != nil {
+			log.Println("error scanning docket entry:", err)
+			return errorMessage
+		}
+		entries = append(entries, entry)
+	}
+
+	return buildDocketMessage(entries)
+}
+
+func refStrings(refs []bverfg.CaseReference) []string {
+	strs := make([]string, len(refs))
+	for i, ref := range refs {
+		strs[i] = ref.String()
+	}
+	return strs
+}
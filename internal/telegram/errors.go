@@ -0,0 +1,73 @@
+package telegram
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// errorClass tells how to deal with a failed request to the Telegram API.
+type errorClass int
+
+const (
+	// errTransient may succeed when retried, e.g. network failures.
+	errTransient errorClass = iota
+	// errRateLimited has to be retried after the advised delay.
+	errRateLimited
+	// errChatGone won't ever succeed for the chat, as the bot has been
+	// blocked, removed or the chat deleted.
+	errChatGone
+	// errPermanent won't succeed as is, e.g. malformed messages.
+	errPermanent
+)
+
+// Reasons for a chat to be inactive.
+const (
+	reasonBlocked     = "blocked"
+	reasonKicked      = "kicked"
+	reasonLeft        = "left"
+	reasonDeactivated = "deactivated"
+	reasonNotFound    = "not found"
+)
+
+// classifyError classifies an error returned by the Telegram API, with the
+// reason for chats being gone.
+func classifyError(err error) (errorClass, string) {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		return errTransient, ""
+	}
+
+	description := strings.ToLower(apiErr.Message)
+	switch {
+	case apiErr.Code == http.StatusTooManyRequests || apiErr.RetryAfter > 0:
+		return errRateLimited, ""
+	case strings.Contains(description, "bot was blocked"):
+		return errChatGone, reasonBlocked
+	case strings.Contains(description, "bot was kicked"),
+		strings.Contains(description, "bot is not a member"):
+		return errChatGone, reasonKicked
+	case strings.Contains(description, "user is deactivated"):
+		return errChatGone, reasonDeactivated
+	case strings.Contains(description, "chat not found"):
+		return errChatGone, reasonNotFound
+	case apiErr.Code >= http.StatusInternalServerError:
+		return errTransient, ""
+	}
+
+	return errPermanent, ""
+}
+
+// retryAfter returns the delay advised by the Telegram API for rate
+// limited requests.
+func retryAfter(err error) time.Duration {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		return 0
+	}
+
+	return time.Duration(apiErr.RetryAfter) * time.Second
+}
@@ -0,0 +1,66 @@
+package telegram
+
+import (
+	"errors"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedClass  errorClass
+		expectedReason string
+	}{
+		{
+			name:           "Blocked by user",
+			err:            &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"},
+			expectedClass:  errChatGone,
+			expectedReason: reasonBlocked,
+		},
+		{
+			name:           "Kicked from group",
+			err:            &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was kicked from the group chat"},
+			expectedClass:  errChatGone,
+			expectedReason: reasonKicked,
+		},
+		{
+			name:           "Deleted chat",
+			err:            &tgbotapi.Error{Code: 400, Message: "Bad Request: chat not found"},
+			expectedClass:  errChatGone,
+			expectedReason: reasonNotFound,
+		},
+		{
+			name: "Rate limited",
+			err: &tgbotapi.Error{
+				Code:               429,
+				Message:            "Too Many Requests: retry after 5",
+				ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 5},
+			},
+			expectedClass: errRateLimited,
+		},
+		{
+			name:          "Malformed message",
+			err:           &tgbotapi.Error{Code: 400, Message: "Bad Request: can't parse entities"},
+			expectedClass: errPermanent,
+		},
+		{
+			name:          "Network failure",
+			err:           errors.New("connection reset by peer"),
+			expectedClass: errTransient,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			class, reason := classifyError(tc.err)
+			assert.Equal(t, tc.expectedClass, class)
+			assert.Equal(t, tc.expectedReason, reason)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jgraeger/bverfgbot/internal/bverfg"
+)
+
+// maxFollowedCases limits the cases a single chat can follow.
+const maxFollowedCases = 50
+
+// handleFollowCommand lets the chat follow a case by its reference.
+func (b *Bot) handleFollowCommand(chatID int64, args string) string {
+	ref, err := bverfg.ParseCaseRefStrict(args)
+	if err != nil {
+		return caseRefErrorMessage(err, followUsageMessage)
+	}
+
+	var count int
+	if err := b.db.QueryRow(b.ctx, countFollowedCasesQuery, chatID).Scan(&count); err != nil {
+		log.Println("error counting followed cases:", err)
+		return errorMessage
+	}
+	if count >= maxFollowedCases {
+		return fmt.Sprintf(tooManyFollowedMessage, maxFollowedCases)
+	}
+
+	if _, err := b.db.Exec(b.ctx, followCaseQuery, chatID, ref.String()); err != nil {
+		log.Println("error following case:", err)
+		return errorMessage
+	}
+
+	return fmt.Sprintf(followedMessage, ref)
+}
+
+// handleUnfollowCommand stops following a case.
+func (b *Bot) handleUnfollowCommand(chatID int64, args string) string {
+	ref, err := bverfg.ParseCaseRefStrict(args)
+	if err != nil {
+		return caseRefErrorMessage(err, unfollowUsageMessage)
+	}
+
+	tag, err := b.db.Exec(b.ctx, unfollowCaseQuery, chatID, ref.String())
+	if err != nil {
+		log.Println("error unfollowing case:", err)
+		return errorMessage
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Sprintf(notFollowingMessage, ref)
+	}
+
+	return fmt.Sprintf(unfollowedMessage, ref)
+}
+
+// handleFollowingCommand lists the cases the chat follows.
+func (b *Bot) handleFollowingCommand(chatID int64) string {
+	rows, err := b.db.Query(b.ctx, getFollowedCasesQuery, chatID)
+	if err != nil {
+		log.Println("error querying followed cases:", err)
+		return errorMessage
+	}
+	defer rows.Close()
+
+	var refs []string
+	for rows.Next() {
+		var ref string
+		if err := rows.Scan(&ref); err != nil {
+			log.Println("error scanning followed case:", err)
+			return errorMessage
+		}
+		refs = append(refs, ref)
+	}
+
+	return buildFollowingMessage(refs)
+}
+
+// caseRefErrorMessage explains why a case reference is invalid, falling
+// back to the usage of the command.
+func caseRefErrorMessage(err error, usage string) string {
+	switch {
+	case errors.Is(err, bverfg.ErrUnknownRegister):
+		return "🤔 Dieses Registerzeichen kenne ich nicht.\n\n" + usage
+	case errors.Is(err, bverfg.ErrInvalidSenate):
+		return "🤔 Der Senat passt nicht zum Registerzeichen.\n\n" + usage
+	case errors.Is(err, bverfg.ErrInvalidYear):
+		return "🤔 Das Jahr des Aktenzeichens liegt in der Zukunft.\n\n" + usage
+	}
+	return usage
+}
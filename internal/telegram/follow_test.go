@@ -0,0 +1,61 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jgraeger/bverfgbot/internal/bverfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaseRefErrorMessage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "Unknown register",
+			err:      fmt.Errorf("parsing %q: %w", "1 BvX 1/20", bverfg.ErrUnknownRegister),
+			expected: "🤔 Dieses Registerzeichen kenne ich nicht.\n\n" + followUsageMessage,
+		},
+		{
+			name:     "Invalid senate",
+			err:      fmt.Errorf("parsing %q: %w", "3 BvR 1/20", bverfg.ErrInvalidSenate),
+			expected: "🤔 Der Senat passt nicht zum Registerzeichen.\n\n" + followUsageMessage,
+		},
+		{
+			name:     "Invalid year",
+			err:      fmt.Errorf("parsing %q: %w", "1 BvR 1/99", bverfg.ErrInvalidYear),
+			expected: "🤔 Das Jahr des Aktenzeichens liegt in der Zukunft.\n\n" + followUsageMessage,
+		},
+		{
+			name:     "Invalid reference",
+			err:      bverfg.ErrInvalidCaseRef,
+			expected: followUsageMessage,
+		},
+		{
+			name:     "Other error",
+			err:      errors.New("unexpected"),
+			expected: followUsageMessage,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expected, caseRefErrorMessage(tc.err, followUsageMessage))
+		})
+	}
+}
+
+func TestCaseRefErrorMessageOfParser(t *testing.T) {
+	_, err := bverfg.ParseCaseRefStrict("1 BvX 1/20")
+	assert.Equal(t, "🤔 Dieses Registerzeichen kenne ich nicht.\n\n"+unfollowUsageMessage,
+		caseRefErrorMessage(err, unfollowUsageMessage))
+
+	_, err = bverfg.ParseCaseRefStrict("irgendwas")
+	assert.Equal(t, unfollowUsageMessage, caseRefErrorMessage(err, unfollowUsageMessage))
+}
@@ -0,0 +1,87 @@
+package telegram
+
+import (
+	"log"
+	"time"
+
+	"github.com/jgraeger/bverfgbot/internal/bverfg"
+)
+
+// handleHearings stores the scheduled oral hearings and notifies about the
+// ones that haven't been known before.
+func (b *Bot) handleHearings() {
+	hearings, err := bverfg.GetUpcomingHearings(b.ctx)
+	if err != nil {
+		// Unparsable hearings are reported, the complete ones are returned anyway
+		log.Println("error getting upcoming hearings:", err)
+	}
+
+	// Don't spam the users with all hearings on the first import
+	var known bool
+	if err := b.db.QueryRow(b.ctx, hasHearingsQuery).Scan(&known); err != nil {
+		log.Println("error querying hearings:", err)
+		return
+	}
+
+	for _, h := range hearings {
+		tag, err := b.db.Exec(b.ctx, storeHearingQuery, h.ID(), h.RefString(), h.Subject, h.Date, h.Location)
+		if err != nil {
+			log.Println("error storing hearing:", err)
+			continue
+		}
+		if tag.RowsAffected() == 0 || !known || h.Date.Before(time.Now()) {
+			continue
+		}
+
+		log.Printf("new hearing %v scheduled. notify.", h.RefString())
+		msg, err := buildNewHearingMessage(h, b.onDocket(h.Refs))
+		if err != nil {
+			log.Printf("error building new hearing message: %v", err)
+			continue
+		}
+		if err := b.enqueue("hearing:"+h.ID(), getDecisionSubscribersQuery, msg, senateAudience(h.Refs).args()...); err != nil {
+			log.Printf("error sending new hearing message: %v", err)
+		}
+	}
+}
+
+// handleTodaysHearings notifies about the stored hearings taking place today.
+func (b *Bot) handleTodaysHearings() {
+	now := time.Now().In(bverfg.CourtLocation())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	rows, err := b.db.Query(b.ctx, getHearingsBetweenQuery, today, today.AddDate(0, 0, 1))
+	if err != nil {
+		log.Println("error querying todays hearings:", err)
+		return
+	}
+	defer rows.Close()
+
+	var hearings []bverfg.AnnouncedHearing
+	for rows.Next() {
+		var (
+			h    bverfg.AnnouncedHearing
+			refs string
+		)
+		if err := rows.Scan(&refs, &h.Subject, &h.Date, &h.Location); err != nil {
+			log.Println("error scanning hearing:", err)
+			return
+		}
+		h.Refs, _ = bverfg.ParseCaseRefs(refs)
+		h.Date = h.Date.In(bverfg.CourtLocation())
+		hearings = append(hearings, h)
+	}
+	rows.Close()
+
+	for _, h := range hearings {
+		log.Printf("hearing %s takes place today. notify.", h.RefString())
+		msg, err := buildHearingTodayMessage(h, b.onDocket(h.Refs))
+		if err != nil {
+			log.Printf("error building hearing message: %v", err)
+			continue
+		}
+		if err := b.enqueue("hearing-today:"+h.ID(), getDecisionSubscribersQuery, msg, senateAudience(h.Refs).args()...); err != nil {
+			log.Printf("error sending hearing message: %v", err)
+		}
+	}
+}
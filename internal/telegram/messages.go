@@ -2,12 +2,23 @@ package telegram
 
 import (
 	"bytes"
+	"html"
+	"strings"
 	"text/template"
+	"unicode/utf8"
 
+	"github.com/goodsign/monday"
 	"github.com/jgraeger/bverfgbot/internal/bverfg"
 	"github.com/mmcdole/gofeed"
 )
 
+const (
+	// maxMessageLength is Telegram's limit of characters per message.
+	maxMessageLength = 4096
+	// headnotesHeaderLength is the length of the headnotes' heading.
+	headnotesHeaderLength = len("\n\n<b>Leitsätze</b>")
+)
+
 type MessageConfig struct {
 	FirstName string
 }
@@ -17,6 +28,16 @@ const welcomeTemplateString = `👋 Hi {{ .FirstName }}!
 
 🔥Und manchmal vielleicht auch vorher... 
 
+📰 Mit /notify kannst du auswählen, ob du Entscheidungen, Pressemitteilungen oder beides erhalten möchtest.
+
+🔎 Mit /follow kannst du einzelnen Verfahren folgen.
+
+⚙️ Mit /settings kannst du Entscheidungen nach Senat und Verfahrensart filtern.
+
+📋 Mit /vorausschau siehst du, welche Verfahren das Gericht dieses Jahr entscheiden möchte.
+
+🔕 Mit /status siehst du deine Einstellungen, mit /stop meldest du dich ab.
+
 Außerdem sage ich dir Bescheid, wenn neue Features zu Verfügen stehen.
 Für Feedback gerne an @rd_io wenden 💻.
 `
@@ -26,7 +47,7 @@ const firstSenateTodayTpl = `Geheimdienste zittern, Pressekammern schlottern!
 Der <b>1. Senat</b>🐻✝️🥦🐺 
 gibt heute eine Entscheidung in nachstehender Sache bekannt:
 <pre>
-{{ .Description }}
+{{ html .Description }}
 </pre>
 Aktenzeichen: {{ .RefString }}
 `
@@ -34,31 +55,226 @@ Aktenzeichen: {{ .RefString }}
 const secondSenateTodayTpl = `🧑‍⚖️ Es Müllert wieder!
 Heute gibt der <b>2. Senat</b> eine Entscheidung in nachstehender Sache bekannt:
 <pre>
-{{ .Description }}
+{{ html .Description }}
 </pre>
 
 Aktenzeichen: {{ .RefString }}
 `
 
+const newHearingTemplateString = `📅 <b>Neuer Verhandlungstermin</b>
+Das Bundesverfassungsgericht verhandelt mündlich in nachstehender Sache:
+<pre>{{ html .Subject }}</pre>
+
+Termin: {{ .Date }}
+{{- if .Location }}
+Ort: {{ html .Location }}
+{{- end }}
+Aktenzeichen: {{ .RefString }}
+{{- if .Docket }}
+📋 Aus der Jahresvorausschau
+{{- end }}
+`
+
+const hearingTodayTemplateString = `🏛 <b>Heute wird verhandelt!</b>
+<pre>{{ html .Subject }}</pre>
+
+Beginn: {{ .Date }}
+{{- if .Location }}
+Ort: {{ html .Location }}
+{{- end }}
+Aktenzeichen: {{ .RefString }}
+{{- if .Docket }}
+📋 Aus der Jahresvorausschau
+{{- end }}
+`
+
 const decisionTemplateString = `🦅 <b>Im Namen des Volkes</b> 🦅
 Es wurde nachstehende Entscheidung verkündet:
 
-<i>{{ .Title }}</i>
-<pre>{{ .Description }}</pre>
+<i>{{ html .Title }}</i>
+<pre>{{ html .Description }}</pre>
+{{- if .RefString }}
+Aktenzeichen: {{ .RefString }}
+{{- end }}
+{{- if .Dissenters }}
+⚡️ <b>Mit Sondervotum</b> von {{ join .Dissenters ", " }}
+{{- end }}
+{{- if .Docket }}
+📋 Aus der Jahresvorausschau
+{{- end }}
+{{- if .Headnotes }}
+
+<b>Leitsätze</b>
+{{- range .Headnotes }}
+{{ html . }}
+{{- end }}
+{{- end }}
 
 <a href="{{ .Link }}">Zur Entscheidung</a>
+{{- if .RelatedLink }}
+<a href="{{ .RelatedLink }}">Zur Pressemitteilung</a>
+{{- end }}
+`
+
+const pressReleaseTemplateString = `📰 <b>Pressemitteilung</b> 📰
+
+<i>{{ html .Title }}</i>
+<pre>{{ html .Description }}</pre>
+
+<a href="{{ .Link }}">Zur Pressemitteilung</a>
+{{- if .RelatedLink }}
+<a href="{{ .RelatedLink }}">Zur Entscheidung</a>
+{{- end }}
+`
+
+const mergedTemplateString = `🦅 <b>Im Namen des Volkes</b> 🦅
+Es wurde nachstehende Entscheidung verkündet:
+
+<i>{{ html .Title }}</i>
+<pre>{{ html .Description }}</pre>
+{{- if .RefString }}
+Aktenzeichen: {{ .RefString }}
+{{- end }}
+{{- if .Dissenters }}
+⚡️ <b>Mit Sondervotum</b> von {{ join .Dissenters ", " }}
+{{- end }}
+{{- if .Docket }}
+📋 Aus der Jahresvorausschau
+{{- end }}
+{{- if .Headnotes }}
+
+<b>Leitsätze</b>
+{{- range .Headnotes }}
+{{ html . }}
+{{- end }}
+{{- end }}
+
+📰 <b>Pressemitteilung</b>
+<i>{{ html .PressTitle }}</i>
+
+<a href="{{ .Link }}">Zur Entscheidung</a>
+<a href="{{ .RelatedLink }}">Zur Pressemitteilung</a>
+`
+
+const topicsTemplateString = `🔔 Ab jetzt erhältst du:
+{{ if .Decisions }}✅{{ else }}❌{{ end }} Entscheidungen
+{{ if or .Decisions .Dissents }}✅{{ else }}❌{{ end }} Entscheidungen mit Sondervotum
+{{ if or .Decisions .Docket }}✅{{ else }}❌{{ end }} Entscheidungen und Verhandlungen aus der Jahresvorausschau
+{{ if .PressReleases }}✅{{ else }}❌{{ end }} Pressemitteilungen
+`
+
+const docketTemplateString = `📋 <b>Jahresvorausschau</b>
+{{ range .Entries }}
+<b>{{ .Ref }}</b>: {{ html .Subject }}
+{{- end }}
+{{- if .More }}
+
+… und {{ .More }} weitere Verfahren
+{{- end }}
+`
+
+const notifyUsageMessage = `Worüber möchtest du benachrichtigt werden?
+
+/notify entscheidungen - nur Entscheidungen
+/notify presse - nur Pressemitteilungen
+/notify sondervoten - nur Entscheidungen mit Sondervotum
+/notify vorausschau - nur Verfahren aus der Jahresvorausschau
+/notify alle - Entscheidungen und Pressemitteilungen
+`
+
+const docketUsageMessage = `Welche Verfahren der Jahresvorausschau möchtest du sehen?
+
+/vorausschau - alle Verfahren
+/vorausschau 1 - Verfahren des Ersten Senats
+/vorausschau 2 BvE - Organstreitverfahren des Zweiten Senats
+`
+
+const emptyDocketMessage = `📋 Die Jahresvorausschau enthält keine passenden Verfahren.`
+
+const followUsageMessage = `Welchem Verfahren möchtest du folgen?
+
+/follow 1 BvR 1234/21
+`
+
+const unfollowUsageMessage = `Welchem Verfahren möchtest du nicht mehr folgen?
+
+/unfollow 1 BvR 1234/21
+`
+
+const followedMessage = `🔎 Du folgst jetzt %v. Ich sage dir Bescheid, sobald es Neuigkeiten gibt.`
+
+const unfollowedMessage = `👋 Du folgst %v nicht mehr.`
+
+const notFollowingMessage = `🤷 Du folgst %v gar nicht.`
+
+const tooManyFollowedMessage = `🙅 Du kannst höchstens %d Verfahren folgen.`
+
+const followingTemplateString = `{{ if . }}🔎 Du folgst diesen Verfahren:
+{{ range . }}
+{{ . }}
+{{- end }}
+{{- else }}🔎 Du folgst noch keinem Verfahren. Probier es mit /follow 1 BvR 1234/21
+{{- end }}
 `
 
+const statusTemplateString = `{{ if .Subscribed }}🔔 Du erhältst Benachrichtigungen seit {{ .Since }}.{{ else }}🔕 Du erhältst keine Benachrichtigungen{{ if .Since }} mehr seit {{ .Since }}{{ end }}. Mit /start geht es los.{{ end }}
+
+{{ if .Topics.Decisions }}✅{{ else }}❌{{ end }} Entscheidungen
+{{ if or .Topics.Decisions .Topics.Dissents }}✅{{ else }}❌{{ end }} Entscheidungen mit Sondervotum
+{{ if or .Topics.Decisions .Topics.Docket }}✅{{ else }}❌{{ end }} Entscheidungen und Verhandlungen aus der Jahresvorausschau
+{{ if .Topics.PressReleases }}✅{{ else }}❌{{ end }} Pressemitteilungen
+
+Senate: {{ or (join .Senates ", ") "alle" }}
+Spruchkörper: {{ or (join .Bodies ", ") "alle" }}
+Verfahrensarten: {{ or (join .Types ", ") "alle" }}
+Verfahren, denen du folgst: {{ .Followed }}
+`
+
+const addedToGroupMessage = `👋 Hallo zusammen!
+👨‍⚖️ Ich versorge euch mit den Entscheidungen des Bundesverfassungsgerichts, sobald diese erscheinen.
+
+🔔 Mit /start meldet ihr diese Gruppe an, mit /settings und /notify wählt ihr aus, worüber ihr benachrichtigt werden wollt.`
+
+const stoppedMessage = `🔕 Du erhältst ab jetzt keine Benachrichtigungen mehr. Deine Einstellungen bleiben erhalten, mit /start geht es jederzeit weiter.`
+
+const notSubscribedMessage = `🔕 Du erhältst bereits keine Benachrichtigungen. Mit /start geht es los.`
+
+const settingsMessage = `⚙️ Welche Entscheidungen möchtest du erhalten?
+
+Wähle Senate, Spruchkörper und Verfahrensarten aus. Solange in einer Zeile nichts ausgewählt ist, erhältst du alle. Verfahren, denen du mit /follow folgst, erhältst du immer.`
+
+const errorMessage = `🙈 Da ist leider etwas schiefgelaufen. Bitte versuche es später noch einmal.`
+
 var (
 	welcomeTemplate      *template.Template
 	decisionTemplate     *template.Template
+	pressReleaseTemplate *template.Template
+	mergedTemplate       *template.Template
+	topicsTemplate       *template.Template
+	docketTemplate       *template.Template
+	followingTemplate    *template.Template
+	statusTemplate       *template.Template
+	newHearingTemplate   *template.Template
+	hearingTodayTemplate *template.Template
 	firstSenateTemplate  *template.Template
 	secondSenateTemplate *template.Template
 )
 
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
 func init() {
 	welcomeTemplate, _ = template.New("welcome").Parse(welcomeTemplateString)
-	decisionTemplate, _ = template.New("decision").Parse(decisionTemplateString)
+	decisionTemplate, _ = template.New("decision").Funcs(templateFuncs).Parse(decisionTemplateString)
+	pressReleaseTemplate, _ = template.New("press_release").Parse(pressReleaseTemplateString)
+	mergedTemplate, _ = template.New("merged").Funcs(templateFuncs).Parse(mergedTemplateString)
+	topicsTemplate, _ = template.New("topics").Parse(topicsTemplateString)
+	docketTemplate, _ = template.New("docket").Parse(docketTemplateString)
+	followingTemplate, _ = template.New("following").Parse(followingTemplateString)
+	statusTemplate, _ = template.New("status").Funcs(templateFuncs).Parse(statusTemplateString)
+	newHearingTemplate, _ = template.New("new_hearing").Parse(newHearingTemplateString)
+	hearingTodayTemplate, _ = template.New("hearing_today").Parse(hearingTodayTemplateString)
 	firstSenateTemplate, _ = template.New("first_senate_daily").Parse(firstSenateTodayTpl)
 	secondSenateTemplate, _ = template.New("second_senate_daily").Parse(secondSenateTodayTpl)
 }
@@ -76,6 +292,14 @@ type decisonCfg struct {
 	Title       string
 	Description string
 	Link        string
+	// RelatedLink points to the counterpart of a decision or press release
+	RelatedLink string
+	PressTitle  string
+	RefString   string
+	Headnotes   []string
+	Dissenters  []string
+	// Docket is set for cases on the annual docket preview
+	Docket bool
 }
 
 type upcomingCfg struct {
@@ -91,10 +315,35 @@ func getUpcomingTemplateFor(senate uint8) *template.Template {
 }
 
 func buildUpcomingDecisionMessage(d bverfg.AnnouncedDecision) (string, error) {
-	tpl := getUpcomingTemplateFor(d.Ref.Senate)
+	tpl := getUpcomingTemplateFor(d.Refs[0].Senate)
+
+	var buf bytes.Buffer
+	cfg := upcomingCfg{Description: d.Description, RefString: d.RefString()}
+	if err := tpl.Execute(&buf, cfg); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+type hearingCfg struct {
+	Subject   string
+	Date      string
+	Location  string
+	RefString string
+	Docket    bool
+}
+
+func buildHearingMessage(tpl *template.Template, h bverfg.AnnouncedHearing, dateLayout string, docket bool) (string, error) {
+	cfg := hearingCfg{
+		Subject:   h.Subject,
+		Date:      monday.Format(h.Date, dateLayout, monday.LocaleDeDE),
+		Location:  h.Location,
+		RefString: h.RefString(),
+		Docket:    docket,
+	}
 
 	var buf bytes.Buffer
-	cfg := upcomingCfg{Description: d.Description, RefString: d.Ref.String()}
 	if err := tpl.Execute(&buf, cfg); err != nil {
 		return "", err
 	}
@@ -102,17 +351,270 @@ func buildUpcomingDecisionMessage(d bverfg.AnnouncedDecision) (string, error) {
 	return buf.String(), nil
 }
 
-func buildDecisionMessage(item *gofeed.Item) (string, error) {
+func buildNewHearingMessage(h bverfg.AnnouncedHearing, docket bool) (string, error) {
+	layout := "Monday, 2. January 2006, 15:04 Uhr"
+	if h.Date.Hour() == 0 && h.Date.Minute() == 0 {
+		layout = monday.DefaultFormatDeDEFull
+	}
+	return buildHearingMessage(newHearingTemplate, h, layout, docket)
+}
+
+func buildHearingTodayMessage(h bverfg.AnnouncedHearing, docket bool) (string, error) {
+	layout := "15:04 Uhr"
+	if h.Date.Hour() == 0 && h.Date.Minute() == 0 {
+		layout = "heute"
+	}
+	return buildHearingMessage(hearingTodayTemplate, h, layout, docket)
+}
+
+func buildDecisionMessage(d bverfg.Decision, pressRelease *gofeed.Item, docket bool) (string, error) {
+	cfg := decisonCfg{
+		Title:       d.Title,
+		Description: d.Description,
+		Link:        d.URL,
+		RefString:   d.RefString(),
+		Headnotes:   d.Headnotes(),
+		Dissenters:  d.Dissenters(),
+		Docket:      docket,
+	}
+	if pressRelease != nil {
+		cfg.RelatedLink = pressRelease.Link
+	}
+
+	return executeFitting(decisionTemplate, cfg)
+}
+
+func buildPressReleaseMessage(item *gofeed.Item, decision *bverfg.Decision) (string, error) {
 	cfg := decisonCfg{
 		Title:       item.Title,
 		Description: item.Description,
 		Link:        item.Link,
 	}
+	if decision != nil {
+		cfg.RelatedLink = decision.URL
+	}
 
 	var buf bytes.Buffer
-	if err := decisionTemplate.Execute(&buf, cfg); err != nil {
+	if err := pressReleaseTemplate.Execute(&buf, cfg); err != nil {
 		return "", err
 	}
 
 	return buf.String(), nil
 }
+
+// buildMergedMessage announces a decision together with its press release.
+func buildMergedMessage(decision bverfg.Decision, pressRelease *gofeed.Item, docket bool) (string, error) {
+	cfg := decisonCfg{
+		Title:       decision.Title,
+		Description: decision.Description,
+		Link:        decision.URL,
+		RefString:   decision.RefString(),
+		Headnotes:   decision.Headnotes(),
+		Dissenters:  decision.Dissenters(),
+		Docket:      docket,
+		RelatedLink: pressRelease.Link,
+		PressTitle:  pressRelease.Title,
+	}
+
+	return executeFitting(mergedTemplate, cfg)
+}
+
+// executeFitting renders a decision message within Telegram's length
+// limit. The headnotes get the room left by the rest of the message, which
+// has its description shortened if it doesn't fit on its own.
+func executeFitting(tpl *template.Template, cfg decisonCfg) (string, error) {
+	headnotes := cfg.Headnotes
+	cfg.Headnotes = nil
+
+	var msg string
+	for {
+		var buf bytes.Buffer
+		if err := tpl.Execute(&buf, cfg); err != nil {
+			return "", err
+		}
+		msg = buf.String()
+
+		excess := utf8.RuneCountInString(msg) - maxMessageLength
+		if excess <= 0 || cfg.Description == "" {
+			break
+		}
+		cfg.Description = truncate(cfg.Description, utf8.RuneCountInString(cfg.Description)-excess)
+	}
+
+	budget := maxMessageLength - utf8.RuneCountInString(msg) - headnotesHeaderLength
+	cfg.Headnotes = limitHeadnotes(headnotes, budget)
+	if len(cfg.Headnotes) == 0 {
+		return msg, nil
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, cfg); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// limitHeadnotes keeps as many headnotes as fit into budget characters once
+// escaped, each on its own line.
+func limitHeadnotes(headnotes []string, budget int) []string {
+	var limited []string
+	length := 0
+	for _, h := range headnotes {
+		length += utf8.RuneCountInString(html.EscapeString(h)) + 1
+		if length > budget-2 {
+			if len(limited) == 0 {
+				return nil
+			}
+			return append(limited, "…")
+		}
+		limited = append(limited, h)
+	}
+	return limited
+}
+
+// truncate shortens s to at most n characters, ending with an ellipsis.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n < 1 {
+		return ""
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// maxDocketEntries limits the entries listed in a single message.
+const maxDocketEntries = 30
+
+// docketEntry is a stored entry of the docket preview.
+type docketEntry struct {
+	Ref     string
+	Subject string
+}
+
+// buildDocketMessage lists the entries within Telegram's length limit,
+// leaving out entries that don't fit and shortening the subject of a single
+// entry that doesn't fit on its own.
+func buildDocketMessage(entries []docketEntry) string {
+	if len(entries) == 0 {
+		return emptyDocketMessage
+	}
+
+	cfg := struct {
+		Entries []docketEntry
+		More    int
+	}{Entries: entries}
+	if len(entries) > maxDocketEntries {
+		cfg.Entries = entries[:maxDocketEntries]
+	}
+
+	for {
+		cfg.More = len(entries) - len(cfg.Entries)
+
+		var buf bytes.Buffer
+		if err := docketTemplate.Execute(&buf, cfg); err != nil {
+			return errorMessage
+		}
+		msg := buf.String()
+
+		excess := utf8.RuneCountInString(msg) - maxMessageLength
+		switch {
+		case excess <= 0:
+			return msg
+		case len(cfg.Entries) > 1:
+			cfg.Entries = cfg.Entries[:len(cfg.Entries)-1]
+		case cfg.Entries[0].Subject != "":
+			entry := cfg.Entries[0]
+			entry.Subject = truncate(entry.Subject, utf8.RuneCountInString(entry.Subject)-excess)
+			cfg.Entries = []docketEntry{entry}
+		default:
+			return msg
+		}
+	}
+}
+
+// parseDocketFilter parses the arguments of the /vorausschau command like
+// "1 BvR", both senate and register being optional.
+func parseDocketFilter(args string) (senate int, register string, ok bool) {
+	for _, arg := range strings.Fields(args) {
+		switch {
+		case arg == "1" || arg == "2":
+			senate = int(arg[0] - '0')
+		case bverfg.ProcedureType(arg).SenateBound():
+			register = arg
+		default:
+			return 0, "", false
+		}
+	}
+	return senate, register, true
+}
+
+func buildFollowingMessage(refs []string) string {
+	var buf bytes.Buffer
+	if err := followingTemplate.Execute(&buf, refs); err != nil {
+		return errorMessage
+	}
+
+	return buf.String()
+}
+
+// topics are the kinds of notifications a chat receives.
+type topics struct {
+	Decisions     bool
+	PressReleases bool
+	// Dissents selects decisions with a dissenting opinion, which are
+	// included in Decisions anyway.
+	Dissents bool
+	// Docket selects decisions and hearings of cases on the annual docket
+	// preview, which are included in Decisions anyway.
+	Docket bool
+}
+
+// parseTopics parses the argument of the /notify command.
+func parseTopics(arg string) (topics, bool) {
+	switch strings.ToLower(strings.TrimSpace(arg)) {
+	case "entscheidungen", "decisions":
+		return topics{Decisions: true}, true
+	case "presse", "press":
+		return topics{PressReleases: true}, true
+	case "sondervoten", "sondervotum", "dissents", "dissent":
+		return topics{Dissents: true}, true
+	case "vorausschau", "jahresvorausschau", "docket":
+		return topics{Docket: true}, true
+	case "alle", "all", "both":
+		return topics{Decisions: true, PressReleases: true}, true
+	}
+
+	return topics{}, false
+}
+
+func getTopicsMessage(t topics) string {
+	var buf bytes.Buffer
+	if err := topicsTemplate.Execute(&buf, t); err != nil {
+		return errorMessage
+	}
+
+	return buf.String()
+}
+
+// status is the subscription state and preferences of a chat.
+type status struct {
+	Subscribed bool
+	// Since is the date of the subscription or unsubscription, if known.
+	Since    string
+	Topics   topics
+	Senates  []string
+	Bodies   []string
+	Types    []string
+	Followed int
+}
+
+func buildStatusMessage(s status) string {
+	var buf bytes.Buffer
+	if err := statusTemplate.Execute(&buf, s); err != nil {
+		return errorMessage
+	}
+
+	return buf.String()
+}
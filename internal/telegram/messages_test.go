@@ -0,0 +1,154 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jgraeger/bverfgbot/internal/bverfg"
+	"github.com/mmcdole/gofeed"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildHearingMessagesEscapeHTML(t *testing.T) {
+	h := bverfg.AnnouncedHearing{
+		Refs:     []bverfg.CaseReference{{Senate: 2, Type: bverfg.Organstreit, RunningNumber: 1, Year: 2023}},
+		Subject:  "Bund & Länder <Organstreit>",
+		Date:     time.Date(2023, 3, 1, 10, 0, 0, 0, bverfg.CourtLocation()),
+		Location: "Sitzungssaal <Karlsruhe>",
+	}
+
+	for _, build := range []func(bverfg.AnnouncedHearing, bool) (string, error){
+		buildNewHearingMessage,
+		buildHearingTodayMessage,
+	} {
+		msg, err := build(h, false)
+		require.NoError(t, err)
+		assert.Contains(t, msg, "<pre>Bund &amp; Länder &lt;Organstreit&gt;</pre>")
+		assert.Contains(t, msg, "Ort: Sitzungssaal &lt;Karlsruhe&gt;")
+	}
+}
+
+func TestBuildPressReleaseMessageEscapesHTML(t *testing.T) {
+	item := &gofeed.Item{
+		Title:       "Bund & Länder",
+		Description: "Anträge nach § 13 Nr. 5 BVerfGG <unzulässig>",
+		Link:        "https://www.bundesverfassungsgericht.de/SharedDocs/Pressemitteilungen/DE/2023/bvg23-001.html",
+	}
+
+	msg, err := buildPressReleaseMessage(item, nil)
+	require.NoError(t, err)
+	assert.Contains(t, msg, "<i>Bund &amp; Länder</i>")
+	assert.Contains(t, msg, "<pre>Anträge nach § 13 Nr. 5 BVerfGG &lt;unzulässig&gt;</pre>")
+	assert.Contains(t, msg, `<a href="`+item.Link+`">Zur Pressemitteilung</a>`)
+
+	decision := bverfg.DecisionFromItem(&gofeed.Item{Title: "Urteil - 2 BvE 1/23"})
+	msg, err = buildMergedMessage(decision, item, false)
+	require.NoError(t, err)
+	assert.Contains(t, msg, "<i>Bund &amp; Länder</i>")
+}
+
+func TestBuildDecisionMessageFitsLimit(t *testing.T) {
+	decision := bverfg.DecisionFromItem(&gofeed.Item{
+		Title:       "Urteil vom 1. März 2023 - 2 BvE 1/23",
+		Description: strings.Repeat("Organstreit & ", 400),
+	})
+	decision.Details = &bverfg.DecisionDetails{
+		Headnotes: []string{strings.Repeat("Leitsatz ", 200), strings.Repeat("Leitsatz ", 200)},
+	}
+	press := &gofeed.Item{Title: strings.Repeat("Pressemitteilung ", 20)}
+
+	msg, err := buildDecisionMessage(decision, press, true)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, utf8.RuneCountInString(msg), maxMessageLength)
+	assert.Contains(t, msg, "…")
+
+	msg, err = buildMergedMessage(decision, press, true)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, utf8.RuneCountInString(msg), maxMessageLength)
+}
+
+func TestBuildDocketMessage(t *testing.T) {
+	entry := docketEntry{Ref: "1 BvR 1/23", Subject: "Bund & Länder"}
+	msg := buildDocketMessage([]docketEntry{entry})
+	assert.Contains(t, msg, "<b>1 BvR 1/23</b>: Bund &amp; Länder")
+	assert.NotContains(t, msg, "weitere Verfahren")
+
+	entries := make([]docketEntry, maxDocketEntries+5)
+	for i := range entries {
+		entries[i] = docketEntry{Ref: "1 BvR 1/23", Subject: "Bund & Länder"}
+	}
+	msg = buildDocketMessage(entries)
+	assert.Contains(t, msg, "… und 5 weitere Verfahren")
+
+	// Long subjects leave out entries to fit into a single message
+	for i := range entries {
+		entries[i].Subject = strings.Repeat("Verfassungsbeschwerde & ", 20)
+	}
+	msg = buildDocketMessage(entries)
+	assert.LessOrEqual(t, utf8.RuneCountInString(msg), maxMessageLength)
+	assert.Contains(t, msg, "weitere Verfahren")
+
+	// A single entry too long on its own gets its subject shortened
+	entry.Subject = strings.Repeat("Verfassungsbeschwerde & ", 300)
+	msg = buildDocketMessage([]docketEntry{entry})
+	assert.LessOrEqual(t, utf8.RuneCountInString(msg), maxMessageLength)
+	assert.Contains(t, msg, "…")
+}
+
+func TestLimitHeadnotes(t *testing.T) {
+	headnotes := []string{"Erster Leitsatz.", "Zweiter Leitsatz.", "Dritter Leitsatz."}
+
+	assert.Equal(t, headnotes, limitHeadnotes(headnotes, 100))
+	assert.Equal(t, []string{"Erster Leitsatz.", "…"}, limitHeadnotes(headnotes, 30))
+	assert.Empty(t, limitHeadnotes(headnotes, 10))
+	// Escaping makes the headnote longer
+	assert.Empty(t, limitHeadnotes([]string{"a < b"}, 8))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "Verfassung", truncate("Verfassung", 10))
+	assert.Equal(t, "Verf…", truncate("Verfassung", 5))
+	assert.Equal(t, "", truncate("Verfassung", 0))
+}
+
+func TestBuildStatusMessage(t *testing.T) {
+	msg := buildStatusMessage(status{
+		Subscribed: true,
+		Since:      "1. März 2023",
+		Topics:     topics{PressReleases: true, Dissents: true},
+		Senates:    []string{"1.", "2."},
+		Types:      []string{"BvR", "BvE"},
+		Followed:   3,
+	})
+	assert.Contains(t, msg, "🔔 Du erhältst Benachrichtigungen seit 1. März 2023.")
+	assert.Contains(t, msg, "❌ Entscheidungen\n")
+	assert.Contains(t, msg, "✅ Entscheidungen mit Sondervotum")
+	assert.Contains(t, msg, "❌ Entscheidungen und Verhandlungen aus der Jahresvorausschau")
+	assert.Contains(t, msg, "✅ Pressemitteilungen")
+	assert.Contains(t, msg, "Senate: 1., 2.\n")
+	assert.Contains(t, msg, "Spruchkörper: alle\n")
+	assert.Contains(t, msg, "Verfahrensarten: BvR, BvE\n")
+	assert.Contains(t, msg, "Verfahren, denen du folgst: 3")
+
+	msg = buildStatusMessage(status{Since: "2. März 2023"})
+	assert.Contains(t, msg, "🔕 Du erhältst keine Benachrichtigungen mehr seit 2. März 2023. Mit /start geht es los.")
+
+	// Chats which never subscribed
+	msg = buildStatusMessage(status{})
+	assert.Contains(t, msg, "🔕 Du erhältst keine Benachrichtigungen. Mit /start geht es los.")
+	assert.Contains(t, msg, "Senate: alle\n")
+	assert.Contains(t, msg, "Spruchkörper: alle\n")
+	assert.Contains(t, msg, "Verfahrensarten: alle\n")
+	assert.Contains(t, msg, "Verfahren, denen du folgst: 0")
+}
+
+func TestBuildFollowingMessage(t *testing.T) {
+	msg := buildFollowingMessage([]string{"1 BvR 2649/21", "2 BvE 1/23"})
+	assert.Equal(t, "🔎 Du folgst diesen Verfahren:\n\n1 BvR 2649/21\n2 BvE 1/23\n", msg)
+
+	msg = buildFollowingMessage(nil)
+	assert.Equal(t, "🔎 Du folgst noch keinem Verfahren. Probier es mit /follow 1 BvR 1234/21\n", msg)
+}
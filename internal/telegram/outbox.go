@@ -0,0 +1,205 @@
+package telegram
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// Due messages are fetched in batches, sent within the rate limits
+	outboxBatchSize = 30
+	outboxInterval  = time.Second
+
+	// Failed messages are retried with exponential backoff up to
+	// maxSendAttempts times.
+	maxSendAttempts = 5
+	retryBaseDelay  = 30 * time.Second
+	retryMaxDelay   = 30 * time.Minute
+)
+
+// outboxMessage is a pending message of a broadcast to a single chat.
+type outboxMessage struct {
+	id       int64
+	chatID   int64
+	text     string
+	attempts int
+}
+
+// enqueue stores msg for all chats selected by query with args in the
+// outbox, from where it is sent by outboxLoop.
+func (b *Bot) enqueue(subject string, query string, msg string, args ...interface{}) error {
+	rows, err := b.db.Query(b.ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("querying chats: %w", err)
+	}
+	defer rows.Close()
+
+	var chats []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("scanning row: %w", err)
+		}
+		chats = append(chats, id)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("querying chats: %w", err)
+	}
+	rows.Close()
+
+	if len(chats) == 0 {
+		return nil
+	}
+
+	tag, err := b.db.Exec(b.ctx, enqueueQuery, subject, chats, msg)
+	if err != nil {
+		return fmt.Errorf("enqueueing %s: %w", subject, err)
+	}
+
+	log.Printf("enqueued %s for %d of %d chats", subject, tag.RowsAffected(), len(chats))
+	return nil
+}
+
+// outboxLoop sends the pending messages of the outbox, including the ones
+// left over by a previous run.
+func (b *Bot) outboxLoop() {
+	ticker := time.NewTicker(outboxInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// Keep on sending as long as there are due messages
+			for {
+				if n := b.drainOutbox(); n < outboxBatchSize || b.ctx.Err() != nil {
+					break
+				}
+			}
+		case <-b.ctx.Done():
+			log.Printf("shutdown outbox loop")
+			return
+		}
+	}
+}
+
+// drainOutbox sends a batch of due messages, returning their number.
+func (b *Bot) drainOutbox() int {
+	rows, err := b.db.Query(b.ctx, getDueMessagesQuery, outboxBatchSize)
+	if err != nil {
+		log.Println("error querying outbox:", err)
+		return 0
+	}
+	defer rows.Close()
+
+	var messages []outboxMessage
+	for rows.Next() {
+		var m outboxMessage
+		if err := rows.Scan(&m.id, &m.chatID, &m.text, &m.attempts); err != nil {
+			log.Println("error scanning outbox message:", err)
+			return 0
+		}
+		messages = append(messages, m)
+	}
+	rows.Close()
+
+	for _, m := range messages {
+		tgMsg := tgbotapi.NewMessage(m.chatID, m.text)
+		tgMsg.ParseMode = tgbotapi.ModeHTML
+
+		err := b.send(m.chatID, tgMsg)
+		if err == nil {
+			if _, err := b.db.Exec(b.ctx, markMessageSentQuery, m.id); err != nil {
+				log.Println("error marking message sent:", err)
+			}
+			continue
+		}
+
+		if b.ctx.Err() != nil {
+			// Shutting down, the message is sent after the restart
+			return 0
+		}
+		log.Printf("error sending msg to chat %d: %v", m.chatID, err)
+		b.handleSendError(m, err)
+	}
+
+	return len(messages)
+}
+
+// sendAction is what becomes of an outbox message whose sending failed.
+type sendAction int
+
+const (
+	retrySend sendAction = iota
+	failSend
+	// failChat gives up on all pending messages to the chat, as it's gone.
+	failChat
+)
+
+// sendFailure decides how to deal with a failed outbox message.
+type sendFailure struct {
+	action   sendAction
+	attempts int
+	retryAt  time.Time
+	// reason why the chat is gone
+	reason string
+}
+
+func decideSendFailure(m outboxMessage, err error, now time.Time) sendFailure {
+	class, reason := classifyError(err)
+	attempts := m.attempts + 1
+
+	switch class {
+	case errRateLimited:
+		// Not the message's fault, so it doesn't count as an attempt
+		return sendFailure{action: retrySend, attempts: m.attempts, retryAt: now.Add(retryAfter(err))}
+	case errChatGone:
+		return sendFailure{action: failChat, attempts: attempts, reason: reason}
+	case errTransient:
+		if attempts < maxSendAttempts {
+			return sendFailure{action: retrySend, attempts: attempts, retryAt: now.Add(retryDelay(attempts))}
+		}
+	}
+
+	return sendFailure{action: failSend, attempts: attempts}
+}
+
+// handleSendError schedules a retry of the message or gives up on it.
+func (b *Bot) handleSendError(m outboxMessage, err error) {
+	failure := decideSendFailure(m, err, time.Now())
+	cause := err.Error()
+
+	switch failure.action {
+	case retrySend:
+		b.retryMessage(m.id, failure.attempts, failure.retryAt, cause)
+	case failChat:
+		b.markChatInactive(m.chatID, failure.reason)
+		if _, err := b.db.Exec(b.ctx, failPendingMessagesQuery, m.chatID, cause); err != nil {
+			log.Println("error failing pending messages:", err)
+		}
+	case failSend:
+		if _, err := b.db.Exec(b.ctx, markMessageFailedQuery, m.id, failure.attempts, cause); err != nil {
+			log.Println("error marking message failed:", err)
+		}
+	}
+}
+
+func (b *Bot) retryMessage(id int64, attempts int, at time.Time, cause string) {
+	if _, err := b.db.Exec(b.ctx, retryMessageQuery, id, attempts, at, cause); err != nil {
+		log.Println("error scheduling message retry:", err)
+	}
+}
+
+// retryDelay doubles the delay with every failed attempt.
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,88 @@
+package telegram
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, retryDelay(1))
+	assert.Equal(t, time.Minute, retryDelay(2))
+	assert.Equal(t, 4*time.Minute, retryDelay(4))
+	assert.Equal(t, retryMaxDelay, retryDelay(20))
+}
+
+func TestDecideSendFailure(t *testing.T) {
+	now := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	rateLimited := &tgbotapi.Error{
+		Code:               429,
+		Message:            "Too Many Requests: retry after 7",
+		ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 7},
+	}
+	blocked := &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}
+	malformed := &tgbotapi.Error{Code: 400, Message: "Bad Request: can't parse entities"}
+	network := errors.New("connection reset by peer")
+
+	testCases := []struct {
+		name     string
+		attempts int
+		err      error
+		expected sendFailure
+	}{
+		{
+			name:     "Rate limited doesn't count as attempt",
+			attempts: 2,
+			err:      rateLimited,
+			expected: sendFailure{action: retrySend, attempts: 2, retryAt: now.Add(7 * time.Second)},
+		},
+		{
+			name:     "Rate limited after the last attempt",
+			attempts: maxSendAttempts - 1,
+			err:      rateLimited,
+			expected: sendFailure{action: retrySend, attempts: maxSendAttempts - 1, retryAt: now.Add(7 * time.Second)},
+		},
+		{
+			name:     "Transient error is retried",
+			attempts: 0,
+			err:      network,
+			expected: sendFailure{action: retrySend, attempts: 1, retryAt: now.Add(retryBaseDelay)},
+		},
+		{
+			name:     "Transient error backs off",
+			attempts: maxSendAttempts - 2,
+			err:      network,
+			expected: sendFailure{action: retrySend, attempts: maxSendAttempts - 1, retryAt: now.Add(retryDelay(maxSendAttempts - 1))},
+		},
+		{
+			name:     "Transient error on the last attempt",
+			attempts: maxSendAttempts - 1,
+			err:      network,
+			expected: sendFailure{action: failSend, attempts: maxSendAttempts},
+		},
+		{
+			name:     "Chat gone",
+			attempts: 0,
+			err:      blocked,
+			expected: sendFailure{action: failChat, attempts: 1, reason: reasonBlocked},
+		},
+		{
+			name:     "Permanent error",
+			attempts: 0,
+			err:      malformed,
+			expected: sendFailure{action: failSend, attempts: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			m := outboxMessage{id: 1, chatID: 7, text: "Hallo", attempts: tc.attempts}
+			assert.Equal(t, tc.expected, decideSendFailure(m, tc.err, now))
+		})
+	}
+}
@@ -1,10 +1,374 @@
 package telegram
 
+import "fmt"
+
+// schemaQueries are run on startup to create or upgrade the schema.
+var schemaQueries = []string{
+	createChatsQuery,
+	addChatTopicsQuery,
+	addChatDissentTopicQuery,
+	createHearingsQuery,
+	createDocketEntriesQuery,
+	addChatDocketTopicQuery,
+	createFollowedCasesQuery,
+	addChatFiltersQuery,
+	addChatInactiveQuery,
+	addChatSubscriptionQuery,
+	unsubscribedByDefaultQuery,
+	createOutboxQuery,
+	createOutboxDueIndexQuery,
+	createOutboxSubjectIndexQuery,
+	removeDuplicateOutboxQuery,
+	createOutboxUniqueIndexQuery,
+}
+
+var createChatsQuery string = `
+	CREATE TABLE IF NOT EXISTS chats (
+		id         BIGINT PRIMARY KEY,
+		first_name TEXT,
+		last_name  TEXT
+	);`
+
+var addChatTopicsQuery string = `
+	ALTER TABLE chats
+	ADD COLUMN IF NOT EXISTS notify_decisions BOOLEAN NOT NULL DEFAULT TRUE,
+	ADD COLUMN IF NOT EXISTS notify_press BOOLEAN NOT NULL DEFAULT TRUE;`
+
+var addChatDissentTopicQuery string = `
+	ALTER TABLE chats
+	ADD COLUMN IF NOT EXISTS notify_dissents BOOLEAN NOT NULL DEFAULT FALSE;`
+
+var createHearingsQuery string = `
+	CREATE TABLE IF NOT EXISTS hearings (
+		id         TEXT PRIMARY KEY,
+		refs       TEXT NOT NULL,
+		subject    TEXT NOT NULL,
+		date       TIMESTAMPTZ NOT NULL,
+		location   TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);`
+
+var createDocketEntriesQuery string = `
+	CREATE TABLE IF NOT EXISTS docket_entries (
+		ref          TEXT PRIMARY KEY,
+		senate       SMALLINT NOT NULL,
+		type         TEXT NOT NULL,
+		preview_year INT NOT NULL,
+		subject      TEXT NOT NULL,
+		rapporteur   TEXT NOT NULL
+	);`
+
+var addChatDocketTopicQuery string = `
+	ALTER TABLE chats
+	ADD COLUMN IF NOT EXISTS notify_docket BOOLEAN NOT NULL DEFAULT FALSE;`
+
+var createFollowedCasesQuery string = `
+	CREATE TABLE IF NOT EXISTS followed_cases (
+		chat_id    BIGINT NOT NULL REFERENCES chats (id) ON DELETE CASCADE,
+		ref        TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (chat_id, ref)
+	);`
+
+// Empty filters let all decisions pass.
+var addChatFiltersQuery string = `
+	ALTER TABLE chats
+	ADD COLUMN IF NOT EXISTS filter_senates SMALLINT[] NOT NULL DEFAULT '{}',
+	ADD COLUMN IF NOT EXISTS filter_bodies TEXT[] NOT NULL DEFAULT '{}',
+	ADD COLUMN IF NOT EXISTS filter_types TEXT[] NOT NULL DEFAULT '{}';`
+
+// Inactive chats have blocked the bot, removed it or been deleted.
+var addChatInactiveQuery string = `
+	ALTER TABLE chats
+	ADD COLUMN IF NOT EXISTS inactive_since TIMESTAMPTZ,
+	ADD COLUMN IF NOT EXISTS inactive_reason TEXT;`
+
+// Chats stored before subscriptions became explicit keep being subscribed.
+var addChatSubscriptionQuery string = `
+	ALTER TABLE chats
+	ADD COLUMN IF NOT EXISTS subscribed BOOLEAN NOT NULL DEFAULT TRUE,
+	ADD COLUMN IF NOT EXISTS subscribed_at TIMESTAMPTZ DEFAULT now(),
+	ADD COLUMN IF NOT EXISTS unsubscribed_at TIMESTAMPTZ;`
+
+// New chats have to subscribe with /start.
+var unsubscribedByDefaultQuery string = `
+	ALTER TABLE chats
+	ALTER COLUMN subscribed SET DEFAULT FALSE,
+	ALTER COLUMN subscribed_at DROP DEFAULT;`
+
+// The outbox keeps every message of a broadcast per chat until it is sent,
+// subject tells what the broadcast was about, e.g. a decision.
+var createOutboxQuery string = `
+	CREATE TABLE IF NOT EXISTS outbox (
+		id           BIGSERIAL PRIMARY KEY,
+		subject      TEXT NOT NULL,
+		chat_id      BIGINT NOT NULL REFERENCES chats (id) ON DELETE CASCADE,
+		message      TEXT NOT NULL,
+		status       TEXT NOT NULL DEFAULT 'pending',
+		attempts     INT NOT NULL DEFAULT 0,
+		next_attempt TIMESTAMPTZ NOT NULL DEFAULT now(),
+		last_error   TEXT,
+		created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+		sent_at      TIMESTAMPTZ
+	);`
+
+var createOutboxDueIndexQuery string = `
+	CREATE INDEX IF NOT EXISTS outbox_due_idx
+	ON outbox (next_attempt)
+	WHERE status = 'pending';`
+
+var createOutboxSubjectIndexQuery string = `
+	CREATE INDEX IF NOT EXISTS outbox_subject_idx
+	ON outbox (subject);`
+
+// removeDuplicateOutboxQuery keeps the first message per chat of broadcasts
+// enqueued more than once, before that is prevented by the unique index.
+var removeDuplicateOutboxQuery string = `
+	DELETE FROM outbox o
+	USING outbox earlier
+	WHERE o.subject = earlier.subject
+		AND o.chat_id = earlier.chat_id
+		AND o.id > earlier.id;`
+
+// A broadcast is enqueued again if the publication is announced again
+// after a restart, which mustn't reach the chats twice.
+var createOutboxUniqueIndexQuery string = `
+	CREATE UNIQUE INDEX IF NOT EXISTS outbox_subject_chat_idx
+	ON outbox (subject, chat_id);`
+
 var storeChatQuery string = `
 	INSERT INTO chats
 	VALUES ($1, $2, $3)
 	ON CONFLICT DO NOTHING;`
 
+// receivesBroadcasts selects the subscribed chats still reachable.
+const receivesBroadcasts = `subscribed AND inactive_since IS NULL`
+
 var getAllQuery string = `
 	SELECT id
-	FROM chats;`
+	FROM chats
+	WHERE ` + receivesBroadcasts + `;`
+
+// followsCase selects the chats following any of the case references $n.
+const followsCase = `EXISTS (
+			SELECT 1
+			FROM followed_cases
+			WHERE chat_id = chats.id AND ref = ANY($%[1]d)
+		)`
+
+// passesFilters selects the chats whose filters let a decision of the
+// senate ($3), body ($4) and registers ($5) pass. Unknown values pass.
+const passesFilters = `(
+			($3 = 0 OR cardinality(filter_senates) = 0 OR $3 = ANY(filter_senates))
+			AND ($4 = '' OR cardinality(filter_bodies) = 0 OR $4 = ANY(filter_bodies))
+			AND (cardinality($5::TEXT[]) = 0 OR cardinality(filter_types) = 0 OR filter_types && $5)
+		)`
+
+// followsDecision selects the chats following a decision, taking the
+// arguments of an audience. Chats may follow decisions with dissent, on the
+// docket preview or of single cases exclusively, the latter regardless of
+// their filters.
+var followsDecision = `(
+		((
+			notify_decisions
+			OR (notify_dissents AND $1)
+			OR (notify_docket AND EXISTS (
+				SELECT 1
+				FROM docket_entries
+				WHERE ref = ANY($2)
+			))
+		) AND ` + passesFilters + `)
+		OR ` + fmt.Sprintf(followsCase, 2) + `
+	)`
+
+var getDecisionSubscribersQuery string = `
+	SELECT id
+	FROM chats
+	WHERE ` + receivesBroadcasts + ` AND ` + followsDecision + `;`
+
+// getPressSubscribersQuery takes the case references of the press release.
+var getPressSubscribersQuery string = `
+	SELECT id
+	FROM chats
+	WHERE ` + receivesBroadcasts + ` AND (notify_press OR ` + fmt.Sprintf(followsCase, 1) + `);`
+
+var getDecisionOnlySubscribersQuery string = `
+	SELECT id
+	FROM chats
+	WHERE ` + receivesBroadcasts + ` AND ` + followsDecision + ` AND NOT notify_press;`
+
+var getPressOnlySubscribersQuery string = `
+	SELECT id
+	FROM chats
+	WHERE ` + receivesBroadcasts + ` AND notify_press AND NOT ` + followsDecision + `;`
+
+var getAllTopicsSubscribersQuery string = `
+	SELECT id
+	FROM chats
+	WHERE ` + receivesBroadcasts + ` AND notify_press AND ` + followsDecision + `;`
+
+// markChatInactiveQuery keeps the time the chat was first found inactive.
+var markChatInactiveQuery string = `
+	UPDATE chats
+	SET inactive_since = COALESCE(inactive_since, now()), inactive_reason = $2
+	WHERE id = $1;`
+
+var reactivateChatQuery string = `
+	UPDATE chats
+	SET inactive_since = NULL, inactive_reason = NULL
+	WHERE id = $1 AND inactive_since IS NOT NULL;`
+
+// subscribeQuery keeps the time of the subscription if already subscribed.
+var subscribeQuery string = `
+	UPDATE chats
+	SET subscribed = TRUE, subscribed_at = now(), unsubscribed_at = NULL
+	WHERE id = $1 AND NOT subscribed;`
+
+var unsubscribeQuery string = `
+	UPDATE chats
+	SET subscribed = FALSE, unsubscribed_at = now()
+	WHERE id = $1 AND subscribed;`
+
+var getStatusQuery string = `
+	SELECT subscribed, subscribed_at, unsubscribed_at,
+		notify_decisions, notify_press, notify_dissents, notify_docket,
+		filter_senates, filter_bodies, filter_types,
+		(SELECT count(*) FROM followed_cases WHERE chat_id = chats.id)
+	FROM chats
+	WHERE id = $1;`
+
+var setTopicsQuery string = `
+	UPDATE chats
+	SET notify_decisions = $2, notify_press = $3, notify_dissents = $4, notify_docket = $5
+	WHERE id = $1;`
+
+var hasHearingsQuery string = `
+	SELECT EXISTS (
+		SELECT 1
+		FROM hearings
+	);`
+
+var storeHearingQuery string = `
+	INSERT INTO hearings (id, refs, subject, date, location)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT DO NOTHING;`
+
+var getHearingsBetweenQuery string = `
+	SELECT refs, subject, date, location
+	FROM hearings
+	WHERE date >= $1 AND date < $2
+	ORDER BY date;`
+
+var storeDocketEntryQuery string = `
+	INSERT INTO docket_entries (ref, senate, type, preview_year, subject, rapporteur)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (ref) DO UPDATE
+	SET preview_year = EXCLUDED.preview_year,
+		subject = EXCLUDED.subject,
+		rapporteur = EXCLUDED.rapporteur;`
+
+var isOnDocketQuery string = `
+	SELECT EXISTS (
+		SELECT 1
+		FROM docket_entries
+		WHERE ref = ANY($1)
+	);`
+
+// getDocketEntriesQuery takes an optional senate ($1) and register ($2),
+// listing the entries of the latest preview.
+var getDocketEntriesQuery string = `
+	SELECT ref, subject
+	FROM docket_entries
+	WHERE preview_year = (SELECT max(preview_year) FROM docket_entries)
+		AND ($1 = 0 OR senate = $1)
+		AND ($2 = '' OR type = $2)
+	ORDER BY senate, type, ref;`
+
+var countFollowedCasesQuery string = `
+	SELECT count(*)
+	FROM followed_cases
+	WHERE chat_id = $1;`
+
+var followCaseQuery string = `
+	INSERT INTO followed_cases (chat_id, ref)
+	VALUES ($1, $2)
+	ON CONFLICT DO NOTHING;`
+
+var unfollowCaseQuery string = `
+	DELETE FROM followed_cases
+	WHERE chat_id = $1 AND ref = $2;`
+
+var getFollowedCasesQuery string = `
+	SELECT ref
+	FROM followed_cases
+	WHERE chat_id = $1
+	ORDER BY created_at;`
+
+var getFiltersQuery string = `
+	SELECT filter_senates, filter_bodies, filter_types
+	FROM chats
+	WHERE id = $1;`
+
+var toggleSenateFilterQuery string = `
+	UPDATE chats
+	SET filter_senates = CASE
+		WHEN $2 = ANY(filter_senates) THEN array_remove(filter_senates, $2)
+		ELSE array_append(filter_senates, $2)
+	END
+	WHERE id = $1;`
+
+var toggleBodyFilterQuery string = `
+	UPDATE chats
+	SET filter_bodies = CASE
+		WHEN $2 = ANY(filter_bodies) THEN array_remove(filter_bodies, $2)
+		ELSE array_append(filter_bodies, $2)
+	END
+	WHERE id = $1;`
+
+var toggleTypeFilterQuery string = `
+	UPDATE chats
+	SET filter_types = CASE
+		WHEN $2 = ANY(filter_types) THEN array_remove(filter_types, $2)
+		ELSE array_append(filter_types, $2)
+	END
+	WHERE id = $1;`
+
+var resetFiltersQuery string = `
+	UPDATE chats
+	SET filter_senates = '{}', filter_bodies = '{}', filter_types = '{}'
+	WHERE id = $1;`
+
+// enqueueQuery takes the subject, the chats and the message of a broadcast.
+// Chats the broadcast has already been enqueued for are skipped.
+var enqueueQuery string = `
+	INSERT INTO outbox (subject, chat_id, message)
+	SELECT $1, unnest($2::BIGINT[]), $3
+	ON CONFLICT (subject, chat_id) DO NOTHING;`
+
+var getDueMessagesQuery string = `
+	SELECT id, chat_id, message, attempts
+	FROM outbox
+	WHERE status = 'pending' AND next_attempt <= now()
+	ORDER BY id
+	LIMIT $1;`
+
+var markMessageSentQuery string = `
+	UPDATE outbox
+	SET status = 'sent', attempts = attempts + 1, sent_at = now(), last_error = NULL
+	WHERE id = $1;`
+
+var retryMessageQuery string = `
+	UPDATE outbox
+	SET attempts = $2, next_attempt = $3, last_error = $4
+	WHERE id = $1;`
+
+var markMessageFailedQuery string = `
+	UPDATE outbox
+	SET status = 'failed', attempts = $2, last_error = $3
+	WHERE id = $1;`
+
+// failPendingMessagesQuery gives up on the messages to a chat that is gone.
+var failPendingMessagesQuery string = `
+	UPDATE outbox
+	SET status = 'failed', last_error = $2
+	WHERE chat_id = $1 AND status = 'pending';`
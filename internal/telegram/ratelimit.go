@@ -0,0 +1,147 @@
+package telegram
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// Telegram allows about 30 messages per second to different chats,
+	// one per second to a single chat and 20 per minute to a group.
+	globalRate  = 30
+	globalBurst = 30
+	chatRate    = 1
+	groupRate   = 20.0 / 60
+
+	// Buckets of chats idle that long are full again and can be dropped.
+	chatBucketIdle = time.Minute
+)
+
+// tokenBucket allows rate requests per second with bursts of up to burst
+// requests.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst float64, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: now}
+}
+
+// delay refills the bucket and returns how long to wait for a token.
+func (t *tokenBucket) delay(now time.Time) time.Duration {
+	if now.After(t.last) {
+		t.tokens += now.Sub(t.last).Seconds() * t.rate
+		if t.tokens > t.burst {
+			t.tokens = t.burst
+		}
+		t.last = now
+	}
+	if t.tokens >= 1 {
+		return 0
+	}
+
+	return time.Duration((1 - t.tokens) / t.rate * float64(time.Second))
+}
+
+// take consumes a token, which has to be available as told by delay.
+func (t *tokenBucket) take() {
+	t.tokens--
+}
+
+// rateLimiter keeps all requests to the Telegram API within a global budget
+// and the budget of each chat. It is safe for concurrent use.
+type rateLimiter struct {
+	mu          sync.Mutex
+	global      *tokenBucket
+	chats       map[int64]*tokenBucket
+	pausedUntil time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		global: newTokenBucket(globalRate, globalBurst, time.Now()),
+		chats:  make(map[int64]*tokenBucket),
+	}
+}
+
+// Wait blocks until a request to chatID is allowed or ctx is done. A zero
+// chatID only takes the global budget into account.
+func (r *rateLimiter) Wait(ctx context.Context, chatID int64) error {
+	for {
+		d := r.reserve(chatID, time.Now())
+		if d == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// reserve takes a token of both the global and the chat's bucket if both
+// have one, otherwise it returns how long to wait before trying again.
+func (r *rateLimiter) reserve(chatID int64, now time.Time) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d := r.pausedUntil.Sub(now)
+	if global := r.global.delay(now); global > d {
+		d = global
+	}
+
+	var chat *tokenBucket
+	if chatID != 0 {
+		chat = r.chatBucket(chatID, now)
+		if delay := chat.delay(now); delay > d {
+			d = delay
+		}
+	}
+	if d > 0 {
+		return d
+	}
+
+	r.global.take()
+	if chat != nil {
+		chat.take()
+	}
+	return 0
+}
+
+func (r *rateLimiter) chatBucket(chatID int64, now time.Time) *tokenBucket {
+	if bucket, ok := r.chats[chatID]; ok {
+		return bucket
+	}
+
+	for id, bucket := range r.chats {
+		if now.Sub(bucket.last) > chatBucketIdle {
+			delete(r.chats, id)
+		}
+	}
+
+	rate := float64(chatRate)
+	if chatID < 0 {
+		rate = groupRate
+	}
+	bucket := newTokenBucket(rate, 1, now)
+	r.chats[chatID] = bucket
+	return bucket
+}
+
+// Pause holds back all requests for d, as told by Telegram's retry_after.
+func (r *rateLimiter) Pause(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(r.pausedUntil) {
+		r.pausedUntil = until
+	}
+}
@@ -0,0 +1,57 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	bucket := newTokenBucket(2, 2, now)
+
+	for i := 0; i < 2; i++ {
+		assert.Zero(t, bucket.delay(now))
+		bucket.take()
+	}
+	assert.Equal(t, 500*time.Millisecond, bucket.delay(now))
+
+	now = now.Add(500 * time.Millisecond)
+	assert.Zero(t, bucket.delay(now))
+	bucket.take()
+
+	// Idle buckets don't fill beyond their burst
+	now = now.Add(time.Hour)
+	assert.Zero(t, bucket.delay(now))
+	assert.Equal(t, 2.0, bucket.tokens)
+}
+
+func TestRateLimiterReserve(t *testing.T) {
+	now := time.Now()
+	limiter := newRateLimiter()
+
+	// A second message to the same chat has to wait, other chats don't
+	assert.Zero(t, limiter.reserve(1, now))
+	assert.Equal(t, time.Second, limiter.reserve(1, now))
+	assert.Zero(t, limiter.reserve(2, now))
+
+	// Groups get one message every three seconds
+	assert.Zero(t, limiter.reserve(-1, now))
+	assert.Equal(t, 3*time.Second, limiter.reserve(-1, now).Round(time.Millisecond))
+
+	// Waiting chats don't use up the global budget
+	for i := 3; i < globalBurst; i++ {
+		assert.Zero(t, limiter.reserve(int64(i), now))
+	}
+	assert.NotZero(t, limiter.reserve(100, now))
+}
+
+func TestRateLimiterPause(t *testing.T) {
+	limiter := newRateLimiter()
+	limiter.Pause(5 * time.Second)
+
+	d := limiter.reserve(0, time.Now())
+	assert.Greater(t, d, 4*time.Second)
+	assert.LessOrEqual(t, d, 5*time.Second)
+}
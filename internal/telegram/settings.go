@@ -0,0 +1,198 @@
+package telegram
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/jgraeger/bverfgbot/internal/bverfg"
+)
+
+const (
+	// Callback data of the settings keyboard, e.g. "filter:senate:1"
+	filterCallbackPrefix = "filter:"
+	senateFilter         = "senate"
+	bodyFilter           = "body"
+	typeFilter           = "type"
+	resetFilter          = "reset"
+
+	typeButtonsPerRow = 4
+)
+
+// filterTypes are the registers offered in the settings, the ones not bound
+// to a senate are hardly ever decided.
+var filterTypes = []bverfg.ProcedureType{
+	bverfg.Verfassungsbeschwerde,
+	bverfg.KonkreteNormenkontrolle,
+	bverfg.AbstrakteNormenkontrolle,
+	bverfg.Organstreit,
+	bverfg.BundLaenderStreit,
+	bverfg.EinstweiligeAnordnung,
+	bverfg.Wahlpruefungsbeschwerde,
+	bverfg.Parteiverbotsverfahren,
+}
+
+// filters are the decisions a chat is notified about. Empty filters let
+// everything pass.
+type filters struct {
+	Senates []int16
+	Bodies  []string
+	Types   []string
+}
+
+func (b *Bot) getFilters(chatID int64) (filters, error) {
+	var f filters
+	if err := b.db.QueryRow(b.ctx, getFiltersQuery, chatID).Scan(&f.Senates, &f.Bodies, &f.Types); err != nil {
+		return filters{}, fmt.Errorf("querying filters: %w", err)
+	}
+	return f, nil
+}
+
+// handleSettingsCommand sends the settings menu.
+func (b *Bot) handleSettingsCommand(msg tgbotapi.Message) {
+	f, err := b.getFilters(msg.Chat.ID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	response := tgbotapi.NewMessage(msg.Chat.ID, settingsMessage)
+	response.ReplyToMessageID = msg.MessageID
+	response.ReplyMarkup = buildSettingsKeyboard(f)
+
+	if err := b.send(msg.Chat.ID, response); err != nil {
+		log.Println("error sending message:", err)
+	}
+}
+
+// handleCallbackQuery toggles a filter of the settings menu.
+func (b *Bot) handleCallbackQuery(query tgbotapi.CallbackQuery) {
+	if query.Message == nil || !strings.HasPrefix(query.Data, filterCallbackPrefix) {
+		return
+	}
+	chatID := query.Message.Chat.ID
+
+	if err := b.toggleFilter(chatID, strings.TrimPrefix(query.Data, filterCallbackPrefix)); err != nil {
+		log.Println("error toggling filter:", err)
+		if err := b.send(0, tgbotapi.NewCallback(query.ID, errorMessage)); err != nil {
+			log.Println("error answering callback:", err)
+		}
+		return
+	}
+
+	f, err := b.getFilters(chatID)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageReplyMarkup(chatID, query.Message.MessageID, buildSettingsKeyboard(f))
+	if err := b.send(chatID, edit); err != nil {
+		log.Println("error updating settings keyboard:", err)
+	}
+	if err := b.send(0, tgbotapi.NewCallback(query.ID, "")); err != nil {
+		log.Println("error answering callback:", err)
+	}
+}
+
+func (b *Bot) toggleFilter(chatID int64, data string) error {
+	query, arg, err := parseFilterToggle(data)
+	if err != nil {
+		return err
+	}
+
+	args := []interface{}{chatID}
+	if arg != nil {
+		args = append(args, arg)
+	}
+	_, err = b.db.Exec(b.ctx, query, args...)
+	return err
+}
+
+// parseFilterToggle returns the query and its argument besides the chat to
+// toggle the filter of the callback data without prefix, e.g. "senate:1".
+func parseFilterToggle(data string) (query string, arg interface{}, err error) {
+	kind, value, _ := strings.Cut(data, ":")
+
+	switch kind {
+	case senateFilter:
+		senate, err := strconv.Atoi(value)
+		if err != nil || (senate != 1 && senate != 2) {
+			return "", nil, fmt.Errorf("invalid senate filter: %v", value)
+		}
+		return toggleSenateFilterQuery, int16(senate), nil
+	case bodyFilter:
+		if value != string(bverfg.Senat) && value != string(bverfg.Kammer) {
+			return "", nil, fmt.Errorf("invalid body filter: %v", value)
+		}
+		return toggleBodyFilterQuery, value, nil
+	case typeFilter:
+		if !bverfg.ProcedureType(value).Known() {
+			return "", nil, fmt.Errorf("invalid type filter: %v", value)
+		}
+		return toggleTypeFilterQuery, value, nil
+	case resetFilter:
+		return resetFiltersQuery, nil, nil
+	}
+
+	return "", nil, fmt.Errorf("unknown filter: %v", data)
+}
+
+func buildSettingsKeyboard(f filters) tgbotapi.InlineKeyboardMarkup {
+	senateButton := func(senate int16, label string) tgbotapi.InlineKeyboardButton {
+		return filterButton(containsInt16(f.Senates, senate), label, senateFilter, strconv.Itoa(int(senate)))
+	}
+	bodyButton := func(body bverfg.DecisionBody, label string) tgbotapi.InlineKeyboardButton {
+		return filterButton(containsString(f.Bodies, string(body)), label, bodyFilter, string(body))
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(senateButton(1, "1. Senat"), senateButton(2, "2. Senat")),
+		tgbotapi.NewInlineKeyboardRow(bodyButton(bverfg.Senat, "Senatsentscheidungen"), bodyButton(bverfg.Kammer, "Kammerentscheidungen")),
+	}
+
+	var row []tgbotapi.InlineKeyboardButton
+	for _, t := range filterTypes {
+		row = append(row, filterButton(containsString(f.Types, string(t)), t.RefSign(), typeFilter, string(t)))
+		if len(row) == typeButtonsPerRow {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔄 Alle Filter zurücksetzen", filterCallbackPrefix+resetFilter),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func filterButton(selected bool, label string, kind string, value string) tgbotapi.InlineKeyboardButton {
+	if selected {
+		label = "✅ " + label
+	}
+	return tgbotapi.NewInlineKeyboardButtonData(label, filterCallbackPrefix+kind+":"+value)
+}
+
+func containsInt16(values []int16, v int16) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
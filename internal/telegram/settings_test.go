@@ -0,0 +1,144 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/jgraeger/bverfgbot/internal/bverfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilterToggle(t *testing.T) {
+	testCases := []struct {
+		data          string
+		expectedQuery string
+		expectedArg   interface{}
+		expectedErr   bool
+	}{
+		{data: "senate:1", expectedQuery: toggleSenateFilterQuery, expectedArg: int16(1)},
+		{data: "senate:2", expectedQuery: toggleSenateFilterQuery, expectedArg: int16(2)},
+		{data: "senate:3", expectedErr: true},
+		{data: "senate:erster", expectedErr: true},
+		{data: "body:Senat", expectedQuery: toggleBodyFilterQuery, expectedArg: "Senat"},
+		{data: "body:Kammer", expectedQuery: toggleBodyFilterQuery, expectedArg: "Kammer"},
+		{data: "body:Plenum", expectedErr: true},
+		{data: "type:BvR", expectedQuery: toggleTypeFilterQuery, expectedArg: "BvR"},
+		{data: "type:PBvU", expectedQuery: toggleTypeFilterQuery, expectedArg: "PBvU"},
+		{data: "type:BvX", expectedErr: true},
+		{data: "reset", expectedQuery: resetFiltersQuery},
+		{data: "unknown:1", expectedErr: true},
+		{data: "", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.data, func(t *testing.T) {
+			t.Parallel()
+			query, arg, err := parseFilterToggle(tc.data)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedQuery, query)
+			assert.Equal(t, tc.expectedArg, arg)
+		})
+	}
+}
+
+func TestBuildSettingsKeyboard(t *testing.T) {
+	keyboard := buildSettingsKeyboard(filters{
+		Senates: []int16{2},
+		Bodies:  []string{string(bverfg.Kammer)},
+		Types:   []string{string(bverfg.Organstreit)},
+	})
+	rows := keyboard.InlineKeyboard
+
+	// Senates, bodies, two rows of types and the reset button
+	assert.Len(t, rows, 5)
+	assert.Equal(t, "1. Senat", rows[0][0].Text)
+	assert.Equal(t, "✅ 2. Senat", rows[0][1].Text)
+	assert.Equal(t, "filter:senate:2", *rows[0][1].CallbackData)
+	assert.Equal(t, "Senatsentscheidungen", rows[1][0].Text)
+	assert.Equal(t, "✅ Kammerentscheidungen", rows[1][1].Text)
+	assert.Equal(t, "filter:body:Kammer", *rows[1][1].CallbackData)
+
+	var types []string
+	for _, row := range rows[2:4] {
+		assert.LessOrEqual(t, len(row), typeButtonsPerRow)
+		for _, button := range row {
+			types = append(types, button.Text)
+		}
+	}
+	assert.Equal(t, []string{"BvR", "BvL", "BvF", "✅ BvE", "BvG", "BvQ", "BvC", "BvB"}, types)
+	assert.Equal(t, "filter:reset", *rows[4][0].CallbackData)
+}
+
+func TestParseDocketFilter(t *testing.T) {
+	testCases := []struct {
+		args             string
+		expectedSenate   int
+		expectedRegister string
+		expectedOK       bool
+	}{
+		{args: "", expectedOK: true},
+		{args: "1", expectedSenate: 1, expectedOK: true},
+		{args: "2 BvE", expectedSenate: 2, expectedRegister: "BvE", expectedOK: true},
+		{args: "BvR", expectedRegister: "BvR", expectedOK: true},
+		{args: "3", expectedOK: false},
+		{args: "PBvU", expectedOK: false},
+		{args: "1 BvX", expectedOK: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.args, func(t *testing.T) {
+			t.Parallel()
+			senate, register, ok := parseDocketFilter(tc.args)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedSenate, senate)
+			assert.Equal(t, tc.expectedRegister, register)
+		})
+	}
+}
+
+func TestAudienceArgs(t *testing.T) {
+	refs := []bverfg.CaseReference{
+		{Senate: 1, Type: bverfg.Verfassungsbeschwerde, RunningNumber: 2649, Year: 2021},
+		{Senate: 1, Type: bverfg.EinstweiligeAnordnung, RunningNumber: 3, Year: 2022},
+	}
+
+	testCases := []struct {
+		name     string
+		audience audience
+		expected []interface{}
+	}{
+		{
+			name:     "Unknown decision",
+			audience: audience{},
+			expected: []interface{}{false, []string{}, int16(0), "", []string{}},
+		},
+		{
+			name: "Chamber decision with dissent",
+			audience: decisionAudience(bverfg.Decision{
+				Refs:    refs,
+				Senate:  1,
+				Body:    bverfg.Kammer,
+				Details: &bverfg.DecisionDetails{Dissents: []bverfg.Dissent{{Judges: []string{"Müller"}}}},
+			}),
+			expected: []interface{}{true, []string{"1 BvR 2649/21", "1 BvQ 3/22"}, int16(1), "Kammer", []string{"BvR", "BvQ"}},
+		},
+		{
+			name:     "Hearing",
+			audience: senateAudience(refs[:1]),
+			expected: []interface{}{false, []string{"1 BvR 2649/21"}, int16(1), "Senat", []string{"BvR"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expected, tc.audience.args())
+		})
+	}
+}
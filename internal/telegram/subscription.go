@@ -0,0 +1,74 @@
+package telegram
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/goodsign/monday"
+	"github.com/jgraeger/bverfgbot/internal/bverfg"
+)
+
+// subscribe lets the chat receive broadcasts, keeping its preferences.
+func (b *Bot) subscribe(chatID int64) error {
+	tag, err := b.db.Exec(b.ctx, subscribeQuery, chatID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() > 0 {
+		log.Printf("chat %d subscribed", chatID)
+	}
+	return nil
+}
+
+// handleStopCommand unsubscribes the chat, keeping its preferences.
+func (b *Bot) handleStopCommand(chatID int64) string {
+	tag, err := b.db.Exec(b.ctx, unsubscribeQuery, chatID)
+	if err != nil {
+		log.Println("error unsubscribing chat:", err)
+		return errorMessage
+	}
+	if tag.RowsAffected() == 0 {
+		return notSubscribedMessage
+	}
+
+	log.Printf("chat %d unsubscribed", chatID)
+	return stoppedMessage
+}
+
+// handleStatusCommand shows whether the chat is subscribed and its
+// preferences.
+func (b *Bot) handleStatusCommand(chatID int64) string {
+	var (
+		s                            status
+		subscribedAt, unsubscribedAt *time.Time
+		f                            filters
+	)
+	err := b.db.QueryRow(b.ctx, getStatusQuery, chatID).Scan(
+		&s.Subscribed, &subscribedAt, &unsubscribedAt,
+		&s.Topics.Decisions, &s.Topics.PressReleases, &s.Topics.Dissents, &s.Topics.Docket,
+		&f.Senates, &f.Bodies, &f.Types,
+		&s.Followed,
+	)
+	if err != nil {
+		log.Println("error querying chat status:", err)
+		return errorMessage
+	}
+
+	since := unsubscribedAt
+	if s.Subscribed {
+		since = subscribedAt
+	}
+	if since != nil {
+		s.Since = monday.Format(since.In(bverfg.CourtLocation()), "2. January 2006", monday.LocaleDeDE)
+	}
+
+	for _, senate := range f.Senates {
+		s.Senates = append(s.Senates, strconv.Itoa(int(senate))+".")
+	}
+	s.Bodies = f.Bodies
+	s.Types = f.Types
+
+	return buildStatusMessage(s)
+}
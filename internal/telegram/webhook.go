@@ -0,0 +1,99 @@
+package telegram
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// secretTokenHeader carries the secret token of the webhook in every
+	// update Telegram posts.
+	secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+	// webhookBuffer is the number of updates received ahead of handling
+	webhookBuffer = 100
+)
+
+// Telegram allows 1-256 characters A-Z, a-z, 0-9, _ and -.
+var secretTokenRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// WebhookConfig lets Telegram post updates to the bot instead of the bot
+// long polling them.
+type WebhookConfig struct {
+	// URL is the public HTTPS URL the bot's webhook handler is reachable at
+	URL string
+	// SecretToken is sent by Telegram with every update to prove its origin
+	SecretToken string
+}
+
+func (c WebhookConfig) validate() error {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook url: %w", err)
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("invalid webhook url %q: must be an absolute https url", c.URL)
+	}
+	if !secretTokenRegex.MatchString(c.SecretToken) {
+		return errors.New("invalid webhook secret token: must be 1-256 characters of A-Z, a-z, 0-9, _ and -")
+	}
+
+	return nil
+}
+
+// setWebhook registers the webhook with Telegram. The secret token isn't
+// supported by the config of the API library.
+func (b *Bot) setWebhook(cfg WebhookConfig) error {
+	params := tgbotapi.Params{
+		"url":          cfg.URL,
+		"secret_token": cfg.SecretToken,
+	}
+	if _, err := b.api.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("setting webhook: %w", err)
+	}
+
+	log.Println("telegram webhook set to:", cfg.URL)
+	return nil
+}
+
+// WebhookHandler receives the updates Telegram posts to the webhook. It is
+// only usable if the bot was created with a WebhookConfig.
+func (b *Bot) WebhookHandler() http.Handler {
+	return http.HandlerFunc(b.serveWebhook)
+}
+
+func (b *Bot) serveWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	token := r.Header.Get(secretTokenHeader)
+	if b.webhookUpdates == nil || subtle.ConstantTimeCompare([]byte(token), []byte(b.secretToken)) != 1 {
+		log.Println("rejected webhook request from:", r.RemoteAddr)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	update, err := b.api.HandleUpdate(r)
+	if err != nil {
+		log.Println("error decoding webhook update:", err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	// Telegram posts the update again unless it's acknowledged
+	select {
+	case b.webhookUpdates <- *update:
+		w.WriteHeader(http.StatusOK)
+	case <-r.Context().Done():
+	case <-b.ctx.Done():
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	}
+}
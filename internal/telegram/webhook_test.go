@@ -0,0 +1,103 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testUpdate = `{"update_id": 42, "message": {"message_id": 1, "chat": {"id": 7, "type": "private"}, "text": "/start"}}`
+
+func newWebhookTestBot() *Bot {
+	return &Bot{
+		ctx:            context.Background(),
+		api:            &tgbotapi.BotAPI{},
+		webhookUpdates: make(chan tgbotapi.Update, 1),
+		secretToken:    "s3cret",
+	}
+}
+
+func TestWebhookHandler(t *testing.T) {
+	testCases := []struct {
+		name           string
+		method         string
+		token          string
+		body           string
+		expectedStatus int
+		expectedUpdate bool
+	}{
+		{
+			name:           "Valid update",
+			method:         http.MethodPost,
+			token:          "s3cret",
+			body:           testUpdate,
+			expectedStatus: http.StatusOK,
+			expectedUpdate: true,
+		},
+		{
+			name:           "Wrong secret token",
+			method:         http.MethodPost,
+			token:          "guess",
+			body:           testUpdate,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Missing secret token",
+			method:         http.MethodPost,
+			body:           testUpdate,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Wrong method",
+			method:         http.MethodGet,
+			token:          "s3cret",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "Malformed update",
+			method:         http.MethodPost,
+			token:          "s3cret",
+			body:           `{"update_id":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			bot := newWebhookTestBot()
+
+			req := httptest.NewRequest(tc.method, "/telegram", strings.NewReader(tc.body))
+			if tc.token != "" {
+				req.Header.Set(secretTokenHeader, tc.token)
+			}
+			rec := httptest.NewRecorder()
+			bot.WebhookHandler().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			if !tc.expectedUpdate {
+				assert.Empty(t, bot.webhookUpdates)
+				return
+			}
+			require.Len(t, bot.webhookUpdates, 1)
+			update := <-bot.webhookUpdates
+			assert.Equal(t, 42, update.UpdateID)
+			assert.Equal(t, int64(7), update.Message.Chat.ID)
+		})
+	}
+}
+
+func TestWebhookConfigValidate(t *testing.T) {
+	assert.NoError(t, WebhookConfig{URL: "https://bot.example.com/telegram", SecretToken: "s3cret_-"}.validate())
+	assert.Error(t, WebhookConfig{URL: "http://bot.example.com/telegram", SecretToken: "s3cret"}.validate())
+	assert.Error(t, WebhookConfig{URL: "/telegram", SecretToken: "s3cret"}.validate())
+	assert.Error(t, WebhookConfig{URL: "https://bot.example.com/telegram"}.validate())
+	assert.Error(t, WebhookConfig{URL: "https://bot.example.com/telegram", SecretToken: "no spaces"}.validate())
+}
@@ -5,20 +5,39 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jgraeger/bverfgbot/internal/bverfg"
 	"github.com/jgraeger/bverfgbot/internal/feed"
 	"github.com/jgraeger/bverfgbot/internal/telegram"
+	"github.com/mmcdole/gofeed"
 )
 
 const (
 	defaultPort = "8000"
+	// defaultCorrelationWindow is how long a Senate decision or press
+	// release is held back to announce it together with its counterpart,
+	// which usually follows within minutes.
+	defaultCorrelationWindow = 5 * time.Minute
+	correlationTick          = time.Minute
+	// decisionPageTimeout bounds scraping a decision page, so notifications
+	// aren't held back by the court's site.
+	decisionPageTimeout = 20 * time.Second
+	// pendingStoreTimeout bounds storing and removing pending feed items.
+	pendingStoreTimeout = 10 * time.Second
+	// shutdownTimeout bounds waiting for webhook requests on shutdown.
+	shutdownTimeout = 5 * time.Second
 
 	decisionFeedURL = "https://www.bundesverfassungsgericht.de/SiteGlobals/Functions/RSSFeed/DE/Entscheidungen/RSSEntscheidungen.xml"
 	pressFeedURL    = "https://www.bundesverfassungsgericht.de/SiteGlobals/Functions/RSSFeed/DE/Pressemitteilungen/RSSPressemitteilungen.xml"
+
+	decisionFeedName = "decisions"
+	pressFeedName    = "press"
 )
 
 var (
@@ -33,36 +52,98 @@ type serveCfg struct {
 	Addr     string
 	BotToken string
 	DSN      string
+
+	CorrelationWindow time.Duration
+
+	// Webhook receives the Telegram updates on Addr, long polling them if
+	// it's nil.
+	Webhook *telegram.WebhookConfig
 }
 
 func serve(ctx context.Context, cfg serveCfg) error {
+	db, err := pgxpool.New(ctx, cfg.DSN)
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+	defer db.Close()
+
 	// Start bot API
-	bot, err := telegram.NewBot(ctx, cfg.BotToken, cfg.DSN)
+	bot, err := telegram.NewBot(ctx, cfg.BotToken, db, cfg.Webhook)
 	if err != nil {
 		log.Fatalln("error creating telegram bot", err)
 	}
 	bot.DoNothing()
 
-	decisionFeed := feed.NewFeed(ctx, decisionFeedURL)
-	decisionFeed.SetRefreshInterval(5 * time.Second)
-	decisionFeed.SetTranslator(bverfg.NewFeedTranslator())
+	serverErrs := make(chan error, 1)
+	if cfg.Webhook != nil {
+		server, err := newWebhookServer(cfg.Addr, *cfg.Webhook, bot)
+		if err != nil {
+			return err
+		}
+		go func() {
+			log.Println("listening for telegram webhook on:", cfg.Addr)
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				serverErrs <- fmt.Errorf("serving webhook: %w", err)
+			}
+		}()
+		defer shutdownServer(server)
+	}
 
-	feedCh := decisionFeed.Subscribe()
+	seenStore, err := feed.NewPostgresStore(ctx, db)
+	if err != nil {
+		return fmt.Errorf("creating feed store: %w", err)
+	}
+
+	feeds := feed.NewManager(ctx, seenStore)
+	feedConfigs := []feed.Config{
+		{
+			Name:            decisionFeedName,
+			URL:             decisionFeedURL,
+			RefreshInterval: 5 * time.Second,
+			Translator:      bverfg.NewFeedTranslator(),
+		},
+		{
+			Name:            pressFeedName,
+			URL:             pressFeedURL,
+			RefreshInterval: 30 * time.Second,
+			Translator:      bverfg.NewFeedTranslator(),
+		},
+	}
+	for _, cfg := range feedConfigs {
+		if err := feeds.Add(cfg); err != nil {
+			return fmt.Errorf("adding feed %v: %w", cfg.Name, err)
+		}
+	}
+
+	pending, err := feed.NewPostgresPendingStore(ctx, db)
+	if err != nil {
+		return fmt.Errorf("creating pending store: %w", err)
+	}
+	pub := &publisher{
+		bot:        bot,
+		correlator: bverfg.NewCorrelator(cfg.CorrelationWindow),
+		pending:    pending,
+	}
+	if err := pub.restore(ctx); err != nil {
+		return fmt.Errorf("restoring pending feed items: %w", err)
+	}
+
+	ticker := time.NewTicker(correlationTick)
+	defer ticker.Stop()
 
-	fmt.Println("Starting...")
-	<-feedCh
 	fmt.Println("Started...")
 	for {
 		select {
-		case feed := <-feedCh:
-			log.Println("new feed item received...")
-			item := feed.Items[0]
-			if item != nil {
-				log.Println("notify bot users about:", item)
-				if err := bot.NotifyDecision(item); err != nil {
-					log.Println("Error sending decision notification:", err)
-				}
+		case e, ok := <-feeds.Events():
+			if !ok {
+				log.Println("feeds closed")
+				return nil
 			}
+			handleFeedEvent(ctx, pub, e)
+		case now := <-ticker.C:
+			pub.expire(now)
+		case err := <-serverErrs:
+			return err
 		case <-ctx.Done():
 			log.Println("server received shutdown signal")
 			return nil
@@ -70,6 +151,93 @@ func serve(ctx context.Context, cfg serveCfg) error {
 	}
 }
 
+// newWebhookServer serves the bot's webhook handler on the path of the
+// webhook's public URL.
+func newWebhookServer(addr string, webhook telegram.WebhookConfig, bot *telegram.Bot) (*http.Server, error) {
+	u, err := url.Parse(webhook.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing webhook url: %w", err)
+	}
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, bot.WebhookHandler())
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}, nil
+}
+
+func shutdownServer(server *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Println("error shutting down webhook server:", err)
+	}
+}
+
+func handleFeedEvent(ctx context.Context, pub *publisher, e feed.SourcedEvent) {
+	if circuit, ok := e.Event.(feed.CircuitEvent); ok {
+		logFeedHealth(e.Source, circuit.Health)
+		return
+	}
+	newItems, ok := e.Event.(feed.NewItemsEvent)
+	if !ok {
+		return
+	}
+	if newItems.Initial {
+		// Don't spam the users with the backlog of a feed we've never seen before
+		log.Printf("initial %v feed with %d items received...", e.Source, len(newItems.Items))
+		newItems.Ack()
+		return
+	}
+	log.Printf("%d new %v feed items received...", len(newItems.Items), e.Source)
+
+	received := time.Now()
+	if err := pub.store(e.Source, newItems.Items, received); err != nil {
+		// Not acknowledged, so the feed publishes the items again after a restart
+		log.Println("error storing feed items:", err)
+	} else {
+		newItems.Ack()
+	}
+
+	// Items are sorted latest first, notify in chronological order
+	for i := len(newItems.Items) - 1; i >= 0; i-- {
+		pub.add(ctx, e.Source, newItems.Items[i], received)
+	}
+}
+
+// loadDecision converts a feed item, adding the headnotes of Senate decisions
+// from the decision page if it can be scraped.
+func loadDecision(ctx context.Context, item *gofeed.Item) bverfg.Decision {
+	d := bverfg.DecisionFromItem(item)
+	if d.Body != bverfg.Senat {
+		return d
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, decisionPageTimeout)
+	defer cancel()
+	if err := d.LoadDetails(ctx); err != nil {
+		log.Printf("error loading details of decision %v: %v", d.RefString(), err)
+	}
+	return d
+}
+
+// logFeedHealth alerts operators about feeds being down for a long time.
+func logFeedHealth(name string, h feed.Health) {
+	if h.State == feed.CircuitOpen {
+		log.Printf("ALERT: %v feed is down since %v after %d failures, last error: %v",
+			name, h.FailingSince.Format(time.RFC3339), h.ConsecutiveFailures, h.LastError)
+		return
+	}
+	log.Printf("%v feed is up again", name)
+}
+
 func main() {
 	flag.Parse()
 
@@ -88,10 +256,29 @@ func main() {
 		port = defaultPort
 	}
 
+	correlationWindow := defaultCorrelationWindow
+	if w := os.Getenv("CORRELATION_WINDOW"); w != "" {
+		d, err := time.ParseDuration(w)
+		if err != nil {
+			log.Fatalf("invalid correlation window %q: %v", w, err)
+		}
+		correlationWindow = d
+	}
+
 	serveCfg := serveCfg{
-		Addr:     fmt.Sprintf(":%s", port),
-		BotToken: token,
-		DSN:      dsn,
+		Addr:              fmt.Sprintf(":%s", port),
+		BotToken:          token,
+		DSN:               dsn,
+		CorrelationWindow: correlationWindow,
+	}
+
+	// Receive updates on a webhook instead of long polling them
+	if webhookURL := os.Getenv("WEBHOOK_URL"); webhookURL != "" {
+		secret := os.Getenv("WEBHOOK_SECRET")
+		if secret == "" {
+			log.Fatal("webhook secret not set")
+		}
+		serveCfg.Webhook = &telegram.WebhookConfig{URL: webhookURL, SecretToken: secret}
 	}
 
 	shutdownCh := make(chan os.Signal, 1)
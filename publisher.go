@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jgraeger/bverfgbot/internal/bverfg"
+	"github.com/jgraeger/bverfgbot/internal/feed"
+	"github.com/jgraeger/bverfgbot/internal/telegram"
+	"github.com/mmcdole/gofeed"
+)
+
+// publisher announces decisions and press releases through the correlator.
+// Feed items are acknowledged once they are added, so every item is kept in
+// the pending store until it has been handed to the bot.
+type publisher struct {
+	bot        *telegram.Bot
+	correlator *bverfg.Correlator
+	pending    feed.PendingStore
+}
+
+// restore adds the items still pending from a previous run.
+func (p *publisher) restore(ctx context.Context) error {
+	items, err := p.pending.Pending(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(items) > 0 {
+		log.Printf("restoring %d pending feed items", len(items))
+	}
+	for _, item := range items {
+		p.add(ctx, item.Feed, item.Item, item.Received)
+	}
+	return nil
+}
+
+// store keeps the received items of the given feed pending until they are
+// announced. It doesn't need the decision pages, so it's done before they
+// are scraped.
+func (p *publisher) store(source string, items []*gofeed.Item, received time.Time) error {
+	// Not bound to the serve context, so items received during shutdown are still stored
+	ctx, cancel := context.WithTimeout(context.Background(), pendingStoreTimeout)
+	defer cancel()
+
+	for _, item := range items {
+		pending := feed.PendingItem{Feed: source, ID: pendingID(source, item), Item: item, Received: received}
+		if err := p.pending.AddPending(ctx, pending); err != nil {
+			return fmt.Errorf("storing pending %v feed item: %w", source, err)
+		}
+	}
+	return nil
+}
+
+// add announces the item of the given feed, unless it's held back.
+func (p *publisher) add(ctx context.Context, source string, item *gofeed.Item, received time.Time) {
+	switch source {
+	case decisionFeedName:
+		p.notify(p.correlator.AddDecision(loadDecision(ctx, item), received))
+	case pressFeedName:
+		p.notify(p.correlator.AddPressRelease(item, received))
+	default:
+		log.Printf("no notification for %v feed item: %v", source, item.Title)
+	}
+}
+
+// expire announces the publications whose correlation window elapsed.
+func (p *publisher) expire(now time.Time) {
+	p.notify(p.correlator.Expired(now))
+}
+
+func (p *publisher) notify(publications []bverfg.Correlated) {
+	for _, c := range publications {
+		if c.Decision != nil {
+			log.Println("notify bot users about decision:", c.Decision.RefString())
+		}
+		if c.PressRelease != nil {
+			log.Println("notify bot users about press release:", c.PressRelease.Title)
+		}
+		if err := p.bot.NotifyCorrelated(c); err != nil {
+			// Kept pending to be announced after a restart
+			log.Println("Error sending notification:", err)
+			continue
+		}
+
+		if c.Decision != nil {
+			p.removePending(decisionFeedName, c.Decision.ID())
+		}
+		if c.PressRelease != nil {
+			p.removePending(pressFeedName, feed.ItemID(c.PressRelease))
+		}
+	}
+}
+
+func (p *publisher) removePending(source string, id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), pendingStoreTimeout)
+	defer cancel()
+	if err := p.pending.RemovePending(ctx, source, id); err != nil {
+		log.Printf("error removing pending %v feed item: %v", source, err)
+	}
+}
+
+// pendingID identifies a pending item the way it's known once it's
+// announced.
+func pendingID(source string, item *gofeed.Item) string {
+	if source == decisionFeedName {
+		return bverfg.DecisionFromItem(item).ID()
+	}
+	return feed.ItemID(item)
+}